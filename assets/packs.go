@@ -0,0 +1,106 @@
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+)
+
+// the file describing a card pack
+const PackManifest = "pack.json"
+
+// a card pack loaded from a directory, holding a manifest and the art it names
+type Pack struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// card definitions in the same format as cards.json, with art relative to the pack
+	Cards json.RawMessage `json:"cards"`
+	// checksum of every file in the pack, so players can check they have the same version
+	Checksum string `json:"-"`
+	files    fs.FS
+}
+
+// loads every pack in a subdirectory of dir. a missing dir just means there are no packs
+func LoadPacks(dir string) ([]*Pack, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var packs []*Pack
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		p, err := LoadPack(os.DirFS(path.Join(dir, e.Name())))
+		if err != nil {
+			return nil, fmt.Errorf("pack %s: %w", e.Name(), err)
+		}
+		packs = append(packs, p)
+	}
+	return packs, nil
+}
+
+// loads a pack from its files
+func LoadPack(files fs.FS) (*Pack, error) {
+	manifest, err := fs.ReadFile(files, PackManifest)
+	if err != nil {
+		return nil, err
+	}
+	p := &Pack{files: files}
+	if err := json.Unmarshal(manifest, p); err != nil {
+		return nil, err
+	}
+	if p.Name == "" {
+		return nil, errors.New("manifest has no name")
+	}
+	if p.Checksum, err = checksum(files); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// hashes the names and contents of every file, in name order
+func checksum(files fs.FS) (string, error) {
+	var names []string
+	err := fs.WalkDir(files, ".", func(name string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			names = append(names, name)
+		}
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	slices.Sort(names)
+	h := sha256.New()
+	for _, name := range names {
+		data, err := fs.ReadFile(files, name)
+		if err != nil {
+			return "", err
+		}
+		h.Write([]byte(name))
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
+// loads the image with the given file name from the pack
+func (p *Pack) LoadArt(name string) (image.Image, error) {
+	f, err := p.files.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	return img, err
+}
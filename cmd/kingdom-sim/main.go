@@ -0,0 +1,13 @@
+// kingdom-sim: plays bot games without a window and reports how each strategy did, like
+// kingdom-of-heaven sim
+package main
+
+import (
+	"os"
+
+	"github.com/zehongharryqu/kingdom-of-heaven/engine"
+)
+
+func main() {
+	engine.RunSim(os.Args[1:])
+}
@@ -0,0 +1,79 @@
+// an optional advisor that shows new players what a bot would do, and why
+package engine
+
+import (
+	"fmt"
+	"slices"
+)
+
+// the strategy the advisor suggests moves from
+var AdvisorStrategy Strategy = DefaultBotSettings.strategy()
+
+// what the advisor suggests and why, e.g. "Miracle: you can afford the top Glory card". ok is
+// false if there is nothing for us to do, the room is ranked or it suggests something illegal
+func (g *Game) Advice() (a Action, reason string, ok bool) {
+	moves := g.LegalMoves()
+	if g.Options.Ranked || len(moves) == 0 {
+		return Action{}, "", false
+	}
+	v := View{g}
+	if g.Decision != -1 {
+		_, skippable := g.PromptDecision()
+		a = Action{Kind: ActionChoose, Card: AdvisorStrategy.decide(v, g.decisionChoices(), skippable)}
+		if a.Card == nil {
+			a = Action{Kind: ActionSkip}
+		}
+	} else {
+		a = AdvisorStrategy.act(v)
+	}
+	if !slices.Contains(moves, a) {
+		return Action{}, "", false
+	}
+	return a, adviceReason(v, a), true
+}
+
+// explains a suggestion in a few words
+func adviceReason(v View, a Action) string {
+	_, faithCards := where(v.owned(), func(c *Card) bool { return slices.Contains(c.CardTypes, FaithType) })
+	switch a.Kind {
+	case ActionPlayWork:
+		if a.Card.works > 0 {
+			return fmt.Sprintf("%s: it gives +%d Works, so play it first", a.Card.Name, a.Card.works)
+		}
+		return a.Card.Name + ": play it while you have Works left"
+	case ActionPlayFaith:
+		if a.Card != nil {
+			return fmt.Sprintf("%s: it gives %d Faith to buy with", a.Card.Name, a.Card.faith)
+		}
+		return "Play All Faith: you need your Faith to buy anything"
+	case ActionBuy:
+		switch {
+		case slices.Contains(a.Card.CardTypes, GloryType) && !slices.ContainsFunc(v.supply(), func(c *Card) bool { return c.glory > a.Card.glory }):
+			return a.Card.Name + ": you can afford the top Glory card"
+		case slices.Contains(a.Card.CardTypes, GloryType):
+			return fmt.Sprintf("%s: %d Glory is the most you can afford", a.Card.Name, a.Card.glory)
+		case slices.Contains(a.Card.CardTypes, FaithType) && 2*len(faithCards) < len(v.owned()):
+			return fmt.Sprintf("%s: you have %d Faith and few Faith cards", a.Card.Name, v.stats().Faith)
+		case slices.Contains(a.Card.CardTypes, FaithType):
+			return fmt.Sprintf("%s: the best Faith card you can afford with %d Faith", a.Card.Name, v.stats().Faith)
+		case len(a.Card.tags) > 0:
+			return fmt.Sprintf("%s: a %s, and you have %d of it", a.Card.Name, a.Card.tags[0], v.count(a.Card.Name))
+		}
+		return a.Card.Name + ": the best verse you can afford"
+	case ActionEndPhase:
+		if v.phase() == WorkPhase {
+			return "End Work Phase: no Works worth playing"
+		}
+		return fmt.Sprintf("End Blessing Phase: nothing worth buying with %d Faith", v.stats().Faith)
+	case ActionChoose:
+		from, purpose := v.decision()
+		switch {
+		case purpose == "play", purpose == "topdeck":
+			return a.Card.Name + ": the most useful card"
+		case from == FromKingdom:
+			return a.Card.Name + ": the most expensive card you can take"
+		}
+		return a.Card.Name + ": the least useful card to lose"
+	}
+	return "Done: none of these are worth it"
+}
@@ -0,0 +1,66 @@
+package engine
+
+import "testing"
+
+// plays faith cards one at a time, so the advisor has a single card to explain
+type oneFaithAtATime struct{ *Personality }
+
+func (s oneFaithAtATime) act(v View) Action {
+	for _, c := range v.hand() {
+		if v.phase() == BlessingPhase && c.faith > 0 {
+			return Action{Kind: ActionPlayFaith, Card: c}
+		}
+	}
+	return s.Personality.act(v)
+}
+
+func TestAdvice(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		strategy Strategy
+		hand     []*Card
+		blessing bool
+		faith    int
+		want     Action
+		reason   string
+	}{
+		{"village first", AdvisorStrategy, []*Card{Study, Festival}, false, 0,
+			Action{Kind: ActionPlayWork, Card: Festival}, "Festival: it gives +2 Works, so play it first"},
+		{"nothing to play", AdvisorStrategy, []*Card{Study, Parable}, false, 0,
+			Action{Kind: ActionEndPhase}, "End Work Phase: no Works worth playing"},
+		{"play all faith", AdvisorStrategy, []*Card{Study, Prayer}, true, 0,
+			Action{Kind: ActionPlayFaith}, "Play All Faith: you need your Faith to buy anything"},
+		{"one faith card", oneFaithAtATime{Personalities[0]}, []*Card{Parable, Prayer}, true, 0,
+			Action{Kind: ActionPlayFaith, Card: Prayer}, "Prayer: it gives 2 Faith to buy with"},
+		{"top glory", AdvisorStrategy, []*Card{Parable}, true, 8,
+			Action{Kind: ActionBuy, Card: Miracle}, "Miracle: you can afford the top Glory card"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func(s Strategy) { AdvisorStrategy = s }(AdvisorStrategy)
+			AdvisorStrategy = tc.strategy
+			w := newTestWorld(t)
+			g := w.games[0]
+			setCards(g, tc.hand, []*Card{Study, Study, Study, Study, Study}, nil)
+			if tc.blessing {
+				playMove(t, w, Action{Kind: ActionEndPhase})
+			}
+			g.Stats.Faith = tc.faith
+			a, reason, ok := g.Advice()
+			if !ok || a != tc.want || reason != tc.reason {
+				t.Errorf("advice %v %q %v, want %v %q", a, reason, ok, tc.want, tc.reason)
+			}
+		})
+	}
+}
+
+func TestNoAdviceInRankedRooms(t *testing.T) {
+	w := newTestWorld(t)
+	g := w.games[0]
+	g.Options.Ranked = true
+	if a, _, ok := g.Advice(); ok {
+		t.Errorf("advised %v in a ranked room", a)
+	}
+	if a, _, ok := w.games[1].Advice(); ok {
+		t.Errorf("advised %v when it isn't our turn", a)
+	}
+}
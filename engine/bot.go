@@ -0,0 +1,177 @@
+// computer players for playing without anyone else
+package engine
+
+import (
+	"slices"
+	"time"
+)
+
+// how long bots wait before each action, so the player can follow what they do
+const BotDelay = 400 * time.Millisecond
+
+// the most bots a player can play against
+const MaxBots = 5
+
+// names for bots, skipping the player's own
+var BotNames = []string{"Ruth", "Boaz", "Esther", "Micah", "Lydia", "Silas"}
+
+// returns n bot names that aren't the player's
+func BotNamesFor(player string, n int) []string {
+	_, names := where(BotNames, func(name string) bool { return name != player })
+	return names[:n]
+}
+
+// how hard bots are to beat
+const (
+	// plays random moves
+	DifficultyBeginner = iota
+	// plays its personality
+	DifficultyNormal
+	// searches ahead, with its personality playing the games out
+	DifficultyHard
+	// searches ahead for longer
+	DifficultyExpert
+)
+
+var DifficultyNames = []string{"Beginner", "Normal", "Hard", "Expert"}
+
+// how many play outs bots search each decision with, by difficulty. searching longer than Expert
+// stops beating Hard, see TestDifficultyLadder
+var DifficultyIterations = map[int]int{DifficultyHard: 100, DifficultyExpert: 200}
+
+// what the host picked for a bot seat
+type BotSettings struct {
+	Difficulty  int
+	Personality *Personality
+}
+
+// every bot starts out playing Big Money without searching
+var DefaultBotSettings = BotSettings{DifficultyNormal, Personalities[0]}
+
+// the strategy a bot with these settings plays
+func (bs BotSettings) strategy() Strategy {
+	switch bs.Difficulty {
+	case DifficultyBeginner:
+		return Random{}
+	case DifficultyHard, DifficultyExpert:
+		return &MCTS{iterations: DifficultyIterations[bs.Difficulty], budget: MCTSBudget, rollout: bs.Personality}
+	}
+	return bs.Personality
+}
+
+// e.g. "Hard, Trial-happy", for the player list
+func (bs BotSettings) String() string {
+	return DifficultyNames[bs.Difficulty] + ", " + bs.Personality.name
+}
+
+// tells everyone the bot's new settings
+func (g *Game) SetBot(name string, bs BotSettings) {
+	g.Conn.Send([]string{SetBot, name, DifficultyNames[bs.Difficulty], bs.Personality.name})
+}
+
+// reads a SetBot message's settings
+func decodeBotSettings(fields []string) BotSettings {
+	bs := BotSettings{slices.Index(DifficultyNames, fields[0]), personalityNamed(fields[1])}
+	if bs.Difficulty == -1 {
+		bs.Difficulty = DefaultBotSettings.Difficulty
+	}
+	return bs
+}
+
+// adds a bot to the room, which readies up and then plays through the same messages as everyone else
+func StartBot(room *LocalRoom, name string, pid int, bs BotSettings) {
+	g := NewGame()
+	g.strategy = bs.strategy()
+	g.botDelay = BotDelay
+	g.State = Lobby
+	g.Room, g.Name = "bots", name
+	conn := room.Join(name, pid)
+	g.Conn = conn
+	conn.Send(append([]string{HasPacks, name}, PackChecksums()...))
+	g.SetBot(name, bs)
+	conn.Send([]string{ToggledReady, name})
+	go g.runBot(conn)
+}
+
+// the names of the bots in the room, sorted like the player list
+func (g *Game) BotsInOrder() []string {
+	var bots []string
+	for name, pd := range g.Players {
+		if pd.Bot != nil {
+			bots = append(bots, name)
+		}
+	}
+	slices.Sort(bots)
+	return bots
+}
+
+// handles every message, acting whenever the bot has seen everything sent so far
+func (g *Game) runBot(conn *LocalClient) {
+	for g.handleMessage(conn.Receive()) {
+		// the kingdom can follow the last ready straight away, so start before it arrives
+		if g.State == Lobby {
+			g.StartIfReady()
+		}
+		// stop once we have sent something, we act again when it comes back
+		for g.State == Playing && conn.caughtUp() && conn.Pending() == 0 && g.botAct() {
+		}
+	}
+}
+
+// handles whatever has arrived and then acts if the bot can, without waiting for anything, returning
+// true if anything happened
+func (g *Game) botStep(conn *LocalClient) bool {
+	stepped := false
+	for !conn.caughtUp() {
+		g.handleMessage(conn.Receive())
+		stepped = true
+	}
+	for conn.Pending() == 0 && g.botAct() {
+		stepped = true
+	}
+	return stepped
+}
+
+// takes one action, returning false if there is nothing for the bot to do right now
+func (g *Game) botAct() bool {
+	if g.State != Playing || g.MyCards == nil || g.Kingdom == nil {
+		return false
+	}
+	if g.Kingdom.GameDone() {
+		g.GameDone()
+		return false
+	}
+	if g.Decision != -1 {
+		g.botDecide()
+		return true
+	}
+	if !g.CanAct() {
+		return false
+	}
+	time.Sleep(g.botDelay)
+	// the strategy is done with the phase if it asks for something it can't do
+	if !g.MakeMove(g.strategy.act(View{g})) {
+		g.MakeMove(Action{Kind: ActionEndPhase})
+	}
+	return true
+}
+
+// makes the current decision with the strategy. if it picks nothing, or something that isn't a
+// choice, the decision is skipped, or the first choice is picked if it can't be. with no choices
+// at all it is skipped anyway, so the bot never gets stuck
+func (g *Game) botDecide() {
+	choices := g.decisionChoices()
+	_, skippable := g.PromptDecision()
+	a := Action{Kind: ActionChoose, Card: g.strategy.decide(View{g}, choices, skippable)}
+	if a.Card == nil {
+		a = Action{Kind: ActionSkip}
+	}
+	if g.MakeMove(a) {
+		return
+	}
+	if moves := g.LegalMoves(); !skippable && len(moves) > 0 {
+		g.MakeMove(moves[0])
+	} else {
+		g.skipDecision()
+	}
+}
@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeBotSettings(t *testing.T) {
+	for fields, want := range map[[2]string]BotSettings{
+		{"Hard", "Collector"}:     {DifficultyHard, personalityNamed("Collector")},
+		{"Beginner", "Big Money"}: {DifficultyBeginner, Personalities[0]},
+		{"Impossible", "Sulky"}:   DefaultBotSettings,
+	} {
+		if bs := decodeBotSettings(fields[:]); bs != want {
+			t.Errorf("%v is %v, want %v", fields, bs, want)
+		}
+	}
+	if s := (BotSettings{DifficultyExpert, personalityNamed("Trial-happy")}).String(); s != "Expert, Trial-happy" {
+		t.Errorf("settings are shown as %q", s)
+	}
+}
+
+// how many games each personality plays at each difficulty against the one below
+const ladderGames = 4
+
+// each difficulty wins most of its games against the one below, over every personality
+func TestDifficultyLadder(t *testing.T) {
+	if testing.Short() {
+		t.Skip("plays searching bots")
+	}
+	// searching by iterations alone, so the same seeds play the same games
+	strategy := func(bs BotSettings) Strategy {
+		s := bs.strategy()
+		if m, ok := s.(*MCTS); ok {
+			m.budget = 0
+		}
+		return s
+	}
+	for d := DifficultyNormal; d <= DifficultyExpert; d++ {
+		var wins float64
+		games := 0
+		for _, p := range Personalities {
+			sc := &SimConfig{kingdom: KingdomPresets[0].Kingdom, rules: Rulesets[0], strategies: []Strategy{strategy(BotSettings{d, p}), strategy(BotSettings{d - 1, p})}}
+			for i := range ladderGames {
+				games++
+				for _, r := range sc.play(int64(i), i%2).players {
+					switch {
+					case r.Name != "p1" || r.Place != 1:
+					case r.Shared:
+						wins += 0.5
+					default:
+						wins++
+					}
+				}
+			}
+		}
+		t.Logf("%s won %v of %d games against %s", DifficultyNames[d], wins, games, DifficultyNames[d-1])
+		if wins <= float64(games)/2 {
+			t.Errorf("%s should win most of its games against %s", DifficultyNames[d], DifficultyNames[d-1])
+		}
+	}
+}
+
+func TestBotNamesFor(t *testing.T) {
+	if got := BotNamesFor("Esther", 3); !slices.Equal(got, []string{"Ruth", "Boaz", "Micah"}) {
+		t.Errorf("bots for Esther are %v", got)
+	}
+	if got := BotNamesFor("Ana", MaxBots); len(got) != MaxBots {
+		t.Errorf("%d bots, want %d", len(got), MaxBots)
+	}
+}
+
+// a player alone in a local room with a bot readies up, picks the kingdom and plays a turn, and the
+// bot plays one back through the same messages
+func TestPlayAgainstBot(t *testing.T) {
+	room := NewLocalRoom()
+	g := NewGame()
+	g.State, g.Room, g.Name, g.RNG = Lobby, "bots", "me", rand.New(rand.NewSource(1))
+	conn := room.Join(g.Name, 0)
+	g.Conn = conn
+	StartBot(room, "Ruth", 1, DefaultBotSettings)
+	conn.Send(append([]string{HasPacks, g.Name}, PackChecksums()...))
+	conn.Send([]string{ToggledReady, g.Name})
+	done := make(chan bool)
+	go func() {
+		for g.State != Playing || g.Turn < 2 {
+			g.handleMessage(conn.Receive())
+			if !conn.caughtUp() {
+				continue
+			}
+			switch {
+			case g.State == Lobby:
+				g.StartIfReady()
+			case g.State == Picking && g.Seed == 0 && len(g.Picks.Picked) > 0:
+				g.Seed = 1
+				conn.Send([]string{SetSeed, "1"})
+				conn.Send(append([]string{SetKingdom}, CardNames(g.Picks.Picked)...))
+			case g.State == Playing && g.CanAct():
+				g.MakeMove(Action{Kind: ActionEndPhase})
+			}
+		}
+		done <- true
+	}()
+	select {
+	case <-done:
+	case <-time.After(20 * time.Second):
+		t.Fatal("the bot never finished its turn")
+	}
+	if bs := g.Players["Ruth"].Bot; bs == nil || *bs != DefaultBotSettings || g.Players["Ruth"].turns != 1 {
+		t.Errorf("Ruth has settings %v after %d turns", bs, g.Players["Ruth"].turns)
+	}
+	if !slices.ContainsFunc(g.ActionLog, func(s string) bool { return strings.HasPrefix(s, "Ruth gained") }) {
+		t.Errorf("Ruth didn't gain anything: %q", g.ActionLog)
+	}
+}
@@ -0,0 +1,300 @@
+package engine
+
+import (
+	"image"
+	"slices"
+	"strings"
+)
+
+type Card struct {
+	Name string
+	// the card's art, or nil to draw it from its rules text
+	ArtBig, ArtSmall   image.Image
+	Cost, glory, faith int
+	// what playing it gives the turn
+	cards, works, blessings int
+	CardTypes               []int
+	// what roles it plays in a kingdom
+	tags []string
+	// which set it is from, and whether it is in every kingdom
+	set   string
+	basic bool
+	// rules text
+	Text string
+	// what it does beyond its stats, or nil if nothing
+	script *Script
+	// the pile it shares with other cards, if it's in a split or mixed pile
+	pile string
+	// how many there are in its pile, or 0 for the usual number
+	pileSize int
+	// whether it's only gained by effects, from a pile outside the supply
+	nonSupply bool
+}
+
+// e.g. "Work - Trial"
+func (c *Card) TypeLine() string {
+	names := make([]string, len(c.CardTypes))
+	for i, t := range c.CardTypes {
+		names[i] = CardTypeNames[t]
+	}
+	return strings.Join(names, " - ")
+}
+
+// breaks rules text into lines no wider than width, as measured by advance, keeping the line breaks
+// it already has. a word wider than width gets a line of its own
+func WrapText(s string, width float64, advance func(line string) float64) string {
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			switch {
+			case line == "":
+				line = word
+			case advance(line+" "+word) > width:
+				lines = append(lines, line)
+				line = word
+			default:
+				line += " " + word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// card types: for sorting hand
+const (
+	TemptationType = 5
+	FaithType      = 3
+	GloryType      = 4
+	WorkType       = 1
+	TrialType      = 0
+	ReactionType   = 2
+)
+
+// card type names, for prompts and the card data file
+var CardTypeNames = map[int]string{
+	TemptationType: "Temptation",
+	FaithType:      "Faith",
+	GloryType:      "Glory",
+	WorkType:       "Work",
+	TrialType:      "Trial",
+	ReactionType:   "Reaction",
+}
+
+// cards the rules refer to
+var (
+	Temptation   = mustCard("Temptation")
+	Study        = mustCard("Study")
+	Prayer       = mustCard("Prayer")
+	Devotion     = mustCard("Devotion")
+	Parable      = mustCard("Parable")
+	Sermon       = mustCard("Sermon")
+	Miracle      = mustCard("Miracle")
+	Bezalel      = mustCard("Bezalel")
+	Stumble      = mustCard("Stumble")
+	Doubt        = mustCard("Doubt")
+	NewCreation  = mustCard("NewCreation")
+	Purification = mustCard("Purification")
+	Feed5000     = mustCard("Feed5000")
+	Festival     = mustCard("Festival")
+	Eden         = mustCard("Eden")
+	LostCoin     = mustCard("LostCoin")
+	Craft        = mustCard("Craft")
+	Collection   = mustCard("Collection")
+	Merchant     = mustCard("Merchant")
+	Belief       = mustCard("Belief")
+	Decree       = mustCard("Decree")
+	GrowFaith    = mustCard("GrowFaith")
+	Shield       = mustCard("Shield")
+	Wisdom       = mustCard("Wisdom")
+	Depletion    = mustCard("Depletion")
+	Transform    = mustCard("Transform")
+	Plan         = mustCard("Plan")
+	Industry     = mustCard("Industry")
+	Duplication  = mustCard("Duplication")
+	Inspiration  = mustCard("Inspiration")
+	Bethlehem    = mustCard("Bethlehem")
+	Desires      = mustCard("Desires")
+	Gift         = mustCard("Gift")
+)
+
+// which cards require decisions: only scripts do
+const (
+	DecisionScript = iota
+)
+
+// where a gained card goes
+const (
+	ToDiscard = iota
+	ToHand
+	ToDeck
+)
+
+// gains a card to the given place
+func (g *Game) gainCard(c *Card, to int) {
+	switch to {
+	case ToHand:
+		g.MyCards.Hand = append(g.MyCards.Hand, c)
+	case ToDeck:
+		g.MyCards.putOnDeck(c)
+	default:
+		g.MyCards.Discard = append(g.MyCards.Discard, c)
+	}
+	// take it from our supply now, so a script gaining again sees the pile without it
+	g.Kingdom.RemoveCard(c.Name)
+	// tell everyone you gained it so all other kingdoms can decrement their supply
+	g.Conn.Send([]string{Gained, g.Name, c.Name})
+}
+
+// releases the card at index i of the hand and returns it
+func (g *Game) releaseFromHand(i int) *Card {
+	c := g.MyCards.Hand[i]
+	g.MyCards.Hand = slices.Delete(g.MyCards.Hand, i, i+1)
+	// tell everyone to add to release pile
+	g.Conn.Send([]string{Released, g.Name, c.Name})
+	return c
+}
+
+// discards cards that everyone can see, e.g. from the deck
+func (g *Game) discardPublicly(cards ...*Card) {
+	g.MyCards.Discard = append(g.MyCards.Discard, cards...)
+	payload := []string{Discarded, g.Name}
+	for _, c := range cards {
+		payload = append(payload, c.Name)
+	}
+	g.Conn.Send(payload)
+}
+
+// returns true if there is a card in hand of the given type
+func (g *Game) handHas(cardType int) bool {
+	return slices.ContainsFunc(g.MyCards.Hand, func(c *Card) bool { return slices.Contains(c.CardTypes, cardType) })
+}
+
+// what runs when you play a card. flag is FreePlay or PlayedAgain if it doesn't use up a work
+func (g *Game) localCardEffect(c *Card, flag string) {
+	payload := []string{Played, g.Name, c.Name}
+	if flag != "" {
+		payload = append(payload, flag)
+	}
+	g.Conn.Send(payload)
+	// everyone adds the card's works, blessings and faith when they see it played, but only we draw
+	if c.cards > 0 {
+		g.MyCards.Hand = g.draw(c.cards, g.MyCards.Hand)
+	}
+	if c.script != nil {
+		// trials wait for everyone else to decide or block
+		if c.script.has(SectionTrial) {
+			g.OtherDecisions += len(g.Players) - 1
+		}
+		g.runScript(c, SectionPlay)
+	} else {
+		g.playAgain()
+	}
+}
+
+// plays the next card a script asked to play again, once the last one is done and nobody is deciding
+func (g *Game) playAgain() {
+	if len(g.replays) == 0 || g.script != nil || g.OtherDecisions > 0 {
+		return
+	}
+	c := g.replays[0]
+	g.replays = g.replays[1:]
+	g.localCardEffect(c, PlayedAgain)
+}
+
+// one other player finished deciding, and if they all have, continues the script that was waiting on them
+func (g *Game) otherDecided() {
+	g.OtherDecisions--
+	if g.OtherDecisions == 0 && g.script != nil && g.script.waiting {
+		g.resumeScript()
+	} else {
+		g.playAgain()
+	}
+}
+
+// returns the other players in turn order, starting with the one to the left of (after) the given player
+func (g *Game) playersLeftOf(name string) []string {
+	i := slices.Index(g.TurnModulus, name)
+	return slices.Concat(g.TurnModulus[i+1:], g.TurnModulus[:i])
+}
+
+// tells everyone to give a card from its pile to each player in order, until the pile runs out
+func (g *Game) distribute(c *Card, players []string) {
+	g.Conn.Send(append([]string{Distributed, g.Name, c.Name}, players...))
+}
+
+// what runs when the local player clicks done on a skippable decision, or picks nothing
+func (g *Game) skipDecision() {
+	g.scriptChose(nil)
+}
+
+// what runs when others play a card
+func (g *Game) reactToCard(c *Card) {
+	if !c.script.has(SectionTrial) {
+		return
+	}
+	// everyone needs to decide
+	g.OtherDecisions += len(g.Players) - 1
+	if g.blockWithShield(c) {
+		return
+	}
+	g.runScript(c, SectionTrial)
+}
+
+// reveals Shield to everyone if we have one, so we are unaffected by the trial
+func (g *Game) blockWithShield(trial *Card) bool {
+	if !slices.Contains(g.MyCards.Hand, Shield) {
+		return false
+	}
+	g.Conn.Send([]string{CardSpecific, g.Name, Shield.Name, trial.Name})
+	return true
+}
+
+// tells everyone else to apply a card's effect on them, outside of trials so Shield doesn't stop it
+func (g *Game) affectOthers(c *Card) {
+	g.OtherDecisions += len(g.Players) - 1
+	g.Conn.Send([]string{AffectOthers, g.Name, c.Name})
+}
+
+// what runs when another player's card affects us outside of a trial. everyone is told what happened
+// so they can stop waiting
+func (g *Game) affectedByCard(c *Card) {
+	if c.script.has(SectionOthers) {
+		g.runScript(c, SectionOthers)
+		return
+	}
+	// nothing happens, but still let everyone know
+	g.Conn.Send([]string{Affected, g.Name, c.Name})
+}
+
+// where the cards for the current decision are picked from
+func (g *Game) DecisionSource() int {
+	return g.script.instr().from
+}
+
+// the different cards that can be picked for the current decision
+func (g *Game) decisionChoices() []*Card {
+	return g.scriptOptions(g.script, g.script.instr())
+}
+
+// the cards shown above the hand: those revealed, or the choices while picking from the discard
+func (g *Game) DecisionRow() []*Card {
+	if g.Decision != -1 && g.DecisionSource() == FromDiscard {
+		return g.decisionChoices()
+	}
+	return g.MyCards.decision
+}
+
+// picks a card for the current decision, which must be one of its choices
+func (g *Game) decide(c *Card) {
+	g.scriptChose(c)
+}
+
+// what message should be shown to the player, and can they skip it?
+func (g *Game) PromptDecision() (string, bool) {
+	if g.Decision == -1 {
+		return "", false
+	}
+	return g.promptScriptChoice()
+}
@@ -0,0 +1,39 @@
+package engine
+
+import "testing"
+
+func TestTypeLine(t *testing.T) {
+	for c, want := range map[*Card]string{Study: "Faith", Doubt: "Work - Trial", Shield: "Work - Reaction"} {
+		if got := c.TypeLine(); got != want {
+			t.Errorf("%s: %q, want %q", c.Name, got, want)
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	chars := func(line string) float64 { return float64(len(line)) }
+	for _, tc := range []struct {
+		s     string
+		width float64
+		want  string
+	}{
+		{"+1 Card", 20, "+1 Card"},
+		{"Gain a card costing up to 4 Faith.", 12, "Gain a card\ncosting up\nto 4 Faith."},
+		{"+1 Card\n+1 Work", 20, "+1 Card\n+1 Work"},
+		{"Look through your discard", 5, "Look\nthrough\nyour\ndiscard"},
+		{"", 10, ""},
+	} {
+		if got := WrapText(tc.s, tc.width, chars); got != tc.want {
+			t.Errorf("WrapText(%q, %v) = %q, want %q", tc.s, tc.width, got, tc.want)
+		}
+	}
+}
+
+// every card can be read without its art
+func TestEveryCardHasRulesText(t *testing.T) {
+	for _, c := range AllCards {
+		if c.Text == "" {
+			t.Errorf("%s has no rules text", c.Name)
+		}
+	}
+}
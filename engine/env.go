@@ -0,0 +1,366 @@
+// a gym-style environment for training agents: an agent plays one seat against bots, one move at a
+// time, and sees the game as a fixed-size list of numbers
+package engine
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strings"
+)
+
+// room for this many cards in the observation and the actions, so their sizes stay the same when
+// packs are loaded. the slots after the last card in AllCards are always 0 and never legal
+const EnvMaxCards = 128
+
+// how many numbers are in an observation, and how many actions there are
+const (
+	EnvObservationSize = EnvTurnFeatures + EnvCardFeatures*EnvMaxCards
+	EnvActionCount     = EnvCardActionsStart + len(EnvCardActions)*EnvMaxCards
+)
+
+// how the observation is laid out: these numbers about the turn, then EnvCardFeatures numbers for
+// each card in AllCards, in order
+const (
+	// whether it is the work or blessing phase
+	EnvWorkPhase = iota
+	EnvBlessingPhase
+	// the active player's works, blessings and faith
+	EnvWorks
+	EnvBlessings
+	EnvFaith
+	// which round it is, counting from 0
+	EnvRound
+	// whether it is our turn, whether we are deciding and whether we can skip the decision
+	EnvOurTurn
+	EnvDeciding
+	EnvSkippable
+	EnvEmptyPiles
+	EnvPlayers
+	// our place in the turn order, counting from 0
+	EnvSeat
+	// then where the choices for our decision come from, one number for each place
+	EnvDecision
+	EnvTurnFeatures = EnvDecision + FromNowhere
+)
+
+// for each card, how many of it are in these places. the other players' are the cards we have seen
+// them get, by seat after ours
+const (
+	EnvInHand = iota
+	EnvInDeck
+	EnvInDiscard
+	EnvInPlay
+	EnvInDecision
+	EnvInSupply
+	EnvOthers
+	EnvCardFeatures = EnvOthers + MaxBots
+)
+
+// how actions are numbered: these, then EnvCardActions for each card in AllCards, in order
+const (
+	EnvEndPhase = iota
+	EnvPlayAllFaith
+	EnvSkip
+	EnvCardActionsStart
+)
+
+// for each card: play it as a work, play it as faith, buy it or pick it for a decision
+var EnvCardActions = [...]int{ActionPlayWork, ActionPlayFaith, ActionBuy, ActionChoose}
+
+// an agent's game against bots
+type Env struct {
+	rules     *Ruleset
+	opponents []Strategy
+	// the agent's game is the one with no strategy
+	w *World
+}
+
+// an environment playing by the named ruleset against a bot for each strategy, e.g. "bigmoney" or
+// "mcts:200". it needs a Reset before anything else
+func NewEnv(rules string, opponents []string) (*Env, error) {
+	e := &Env{rules: RulesetNamed(rules)}
+	if e.rules.Name != rules {
+		return nil, fmt.Errorf("unknown ruleset %s", rules)
+	}
+	if len(opponents) == 0 || len(opponents) > MaxBots {
+		return nil, fmt.Errorf("1 to %d opponents", MaxBots)
+	}
+	if len(AllCards) > EnvMaxCards {
+		return nil, fmt.Errorf("%d cards are loaded but the observation has room for %d", len(AllCards), EnvMaxCards)
+	}
+	for _, spec := range opponents {
+		s, err := strategyNamed(strings.TrimSpace(spec))
+		if err != nil {
+			return nil, err
+		}
+		warnIfTimed(s)
+		e.opponents = append(e.opponents, s)
+	}
+	return e, nil
+}
+
+// the action with the number, or false if there is no such action
+func envAction(i int) (Action, bool) {
+	switch i {
+	case EnvEndPhase:
+		return Action{Kind: ActionEndPhase}, true
+	case EnvPlayAllFaith:
+		return Action{Kind: ActionPlayFaith}, true
+	case EnvSkip:
+		return Action{Kind: ActionSkip}, true
+	}
+	i -= EnvCardActionsStart
+	if i < 0 || i/len(EnvCardActions) >= len(AllCards) {
+		return Action{}, false
+	}
+	return Action{Kind: EnvCardActions[i%len(EnvCardActions)], Card: AllCards[i/len(EnvCardActions)]}, true
+}
+
+// the number of the action
+func envActionIndex(a Action) int {
+	switch {
+	case a.Kind == ActionEndPhase:
+		return EnvEndPhase
+	case a.Kind == ActionPlayFaith && a.Card == nil:
+		return EnvPlayAllFaith
+	case a.Kind == ActionSkip:
+		return EnvSkip
+	}
+	return EnvCardActionsStart + len(EnvCardActions)*slices.Index(AllCards, a.Card) + slices.Index(EnvCardActions[:], a.Kind)
+}
+
+// starts a new game on the kingdom, a preset or verses separated by commas, and plays the bots up
+// to our first move. the seed decides the shuffles, the bots' random choices and who goes first
+func (e *Env) Reset(seed int64, kingdom string) ([]float64, error) {
+	cards, err := kingdomNamed(kingdom)
+	if err != nil {
+		return nil, err
+	}
+	sc := &SimConfig{kingdom: cards, rules: e.rules, strategies: append([]Strategy{nil}, e.opponents...)}
+	e.w = sc.start(seed, int(uint64(seed)%uint64(len(sc.strategies))))
+	e.advance()
+	return e.Observe(), nil
+}
+
+// makes our move and plays the bots up to our next one. the reward is our share of the win once
+// the game is over, and 0 before then or if it went on too long
+func (e *Env) Step(action int) (obs []float64, reward float64, done bool, err error) {
+	if e.w == nil {
+		return nil, 0, false, errors.New("reset before stepping")
+	}
+	me := e.w.games[e.w.me]
+	if a, ok := envAction(action); !ok || !me.MakeMove(a) {
+		return nil, 0, false, fmt.Errorf("action %d is not legal", action)
+	}
+	e.advance()
+	if len(me.LegalMoves()) > 0 && me.Turn < MaxSimRounds*len(e.w.games) {
+		return e.Observe(), 0, false, nil
+	}
+	if me.Result != nil {
+		_, winners := where(me.Result.Players, func(p PlayerResult) bool { return p.Place == 1 })
+		if slices.ContainsFunc(winners, func(p PlayerResult) bool { return p.Name == me.Name }) {
+			reward = 1 / float64(len(winners))
+		}
+	}
+	return e.Observe(), reward, true, nil
+}
+
+// which actions are legal now
+func (e *Env) Mask() []bool {
+	mask := make([]bool, EnvActionCount)
+	for _, a := range e.w.games[e.w.me].LegalMoves() {
+		mask[envActionIndex(a)] = true
+	}
+	return mask
+}
+
+// plays the bots until we have a choice to make or the game is over. moves that are the only legal
+// one are made for us
+func (e *Env) advance() {
+	me, conn := e.w.games[e.w.me], e.w.conns[e.w.me]
+	for {
+		stepped := false
+		for i, g := range e.w.games {
+			if i != e.w.me && g.botStep(e.w.conns[i]) {
+				stepped = true
+			}
+		}
+		for !conn.caughtUp() {
+			me.handleMessage(conn.Receive())
+			stepped = true
+		}
+		if me.State == Playing && me.Kingdom.GameDone() {
+			me.GameDone()
+			continue
+		}
+		moves := me.LegalMoves()
+		switch {
+		case me.Turn >= MaxSimRounds*len(e.w.games):
+			return
+		case len(moves) == 1:
+			me.MakeMove(moves[0])
+		case len(moves) > 1, !stepped:
+			return
+		}
+	}
+}
+
+// what we know about the game, laid out as described by EnvTurnFeatures and EnvCardFeatures
+func (e *Env) Observe() []float64 {
+	g := e.w.games[e.w.me]
+	obs := make([]float64, EnvObservationSize)
+	bit := func(b bool) float64 {
+		if b {
+			return 1
+		}
+		return 0
+	}
+	n := len(g.TurnModulus)
+	seat := slices.Index(g.TurnModulus, g.Name)
+	_, skippable := g.PromptDecision()
+	obs[EnvWorkPhase] = bit(g.Phase == WorkPhase)
+	obs[EnvBlessingPhase] = bit(g.Phase == BlessingPhase)
+	obs[EnvWorks] = float64(g.Stats.Works)
+	obs[EnvBlessings] = float64(g.Stats.Blessings)
+	obs[EnvFaith] = float64(g.Stats.Faith)
+	obs[EnvRound] = float64(g.Turn / n)
+	obs[EnvOurTurn] = bit(g.TurnModulus[g.Turn%n] == g.Name)
+	obs[EnvDeciding] = bit(g.Decision != -1)
+	obs[EnvSkippable] = bit(skippable)
+	obs[EnvEmptyPiles] = float64(g.Kingdom.emptyPiles())
+	obs[EnvPlayers] = float64(n)
+	obs[EnvSeat] = float64(seat)
+	if g.Decision != -1 {
+		obs[EnvDecision+g.DecisionSource()] = 1
+	}
+	count := func(cards []*Card, c *Card) float64 {
+		_, same := where(cards, func(d *Card) bool { return d == c })
+		return float64(len(same))
+	}
+	for i, c := range AllCards {
+		card := obs[EnvTurnFeatures+i*EnvCardFeatures:]
+		card[EnvInHand] = count(g.MyCards.Hand, c)
+		card[EnvInDeck] = count(g.MyCards.Deck, c)
+		card[EnvInDiscard] = count(g.MyCards.Discard, c)
+		card[EnvInPlay] = count(slices.Concat(g.InPlayWork, g.InPlayFaith), c)
+		card[EnvInDecision] = count(g.MyCards.decision, c)
+		for _, p := range g.Kingdom.Piles {
+			card[EnvInSupply] += count(p.cards, c)
+		}
+		for j := 1; j < n; j++ {
+			card[EnvOthers+j-1] = count(g.Players[g.TurnModulus[(seat+j)%n]].cards, c)
+		}
+	}
+	return obs
+}
+
+// a line the trainer sends
+type EnvRequest struct {
+	// reset, step or spec
+	Cmd string `json:"cmd"`
+	// for reset: a kingdom preset or verses separated by commas, the ruleset and the bots
+	Seed      int64    `json:"seed"`
+	Kingdom   string   `json:"kingdom"`
+	Rules     string   `json:"rules"`
+	Opponents []string `json:"opponents"`
+	// for step
+	Action int `json:"action"`
+}
+
+// a line sent back
+type EnvResponse struct {
+	Observation []float64 `json:"observation,omitempty"`
+	Mask        []bool    `json:"mask,omitempty"`
+	Reward      float64   `json:"reward"`
+	Done        bool      `json:"done"`
+	Error       string    `json:"error,omitempty"`
+	// for spec
+	ObservationSize int      `json:"observationSize,omitempty"`
+	Actions         []string `json:"actions,omitempty"`
+	Cards           []string `json:"cards,omitempty"`
+}
+
+// kingdom-of-heaven env: plays the environment over r and w, stdin and stdout, one JSON object per
+// line each way. the game logs everything else, to stderr
+func RunEnv(args []string, r io.Reader, w io.Writer) {
+	fs := flag.NewFlagSet("env", flag.ExitOnError)
+	packDir := fs.String("packs", "packs", "directory of card packs to load")
+	fs.Parse(args)
+	LoadPacks(*packDir)
+
+	var e *Env
+	out := json.NewEncoder(w)
+	in := bufio.NewScanner(r)
+	in.Buffer(nil, 1<<20)
+	for in.Scan() {
+		var req EnvRequest
+		var resp EnvResponse
+		if err := json.Unmarshal(in.Bytes(), &req); err != nil {
+			resp.Error = err.Error()
+		} else if e, resp = handleEnvRequest(e, req); resp.Error == "" && e != nil && req.Cmd != "spec" {
+			resp.Mask = e.Mask()
+		}
+		if err := out.Encode(resp); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// answers one request, returning the environment to use for the next
+func handleEnvRequest(e *Env, req EnvRequest) (*Env, EnvResponse) {
+	var resp EnvResponse
+	switch req.Cmd {
+	case "spec":
+		resp.ObservationSize = EnvObservationSize
+		// unused card slots have no name
+		for i := range EnvActionCount {
+			a, ok := envAction(i)
+			if !ok {
+				resp.Actions = append(resp.Actions, "")
+				continue
+			}
+			resp.Actions = append(resp.Actions, a.String())
+		}
+		resp.Cards = CardNames(AllCards)
+	case "reset":
+		if req.Rules == "" {
+			req.Rules = Rulesets[0].Name
+		}
+		if req.Kingdom == "" {
+			req.Kingdom = KingdomPresets[0].Name
+		}
+		if len(req.Opponents) == 0 {
+			req.Opponents = []string{"bigmoney"}
+		}
+		// a reset that fails leaves the last game as it was
+		next, err := NewEnv(req.Rules, req.Opponents)
+		if err == nil {
+			resp.Observation, err = next.Reset(req.Seed, req.Kingdom)
+		}
+		if err != nil {
+			resp.Error = err.Error()
+			return e, resp
+		}
+		e = next
+	case "step":
+		if e == nil {
+			resp.Error = "reset before stepping"
+			return e, resp
+		}
+		obs, reward, done, err := e.Step(req.Action)
+		if err != nil {
+			resp.Error = err.Error()
+			return e, resp
+		}
+		resp.Observation, resp.Reward, resp.Done = obs, reward, done
+	default:
+		resp.Error = "unknown cmd " + req.Cmd + ", try reset, step or spec"
+	}
+	return e, resp
+}
@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+)
+
+// plays a game with the policy until it is done, checking every observation and mask on the way,
+// and returns the reward at the end
+func playEnv(t *testing.T, e *Env, seed int64, policy func(e *Env, mask []bool) int) float64 {
+	t.Helper()
+	obs, err := e.Reset(seed, KingdomPresets[0].Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 10000 {
+		if len(obs) != EnvObservationSize {
+			t.Fatalf("observation has %d numbers, want %d", len(obs), EnvObservationSize)
+		}
+		mask := e.Mask()
+		if len(mask) != EnvActionCount || !slices.Contains(mask, true) {
+			t.Fatalf("mask has %d actions, %d legal", len(mask), len(slices.DeleteFunc(slices.Clone(mask), func(b bool) bool { return !b })))
+		}
+		var reward float64
+		var done bool
+		obs, reward, done, err = e.Step(policy(e, mask))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if done {
+			return reward
+		}
+		if reward != 0 {
+			t.Fatalf("reward %v before the game is over", reward)
+		}
+	}
+	t.Fatal("the game never ended")
+	return 0
+}
+
+// plays Big Money, or the first legal action if that isn't legal
+func bigMoneyPolicy(e *Env, mask []bool) int {
+	g := e.w.games[e.w.me]
+	var a Action
+	if g.Decision != -1 {
+		a = Action{Kind: ActionChoose, Card: BigMoney{}.decide(View{g}, g.decisionChoices(), false)}
+	} else {
+		a = BigMoney{}.act(View{g})
+	}
+	if i := envActionIndex(a); i >= 0 && i < len(mask) && mask[i] {
+		return i
+	}
+	return slices.Index(mask, true)
+}
+
+// ends every phase without doing anything
+func idlePolicy(e *Env, mask []bool) int {
+	for _, i := range []int{EnvEndPhase, EnvSkip} {
+		if mask[i] {
+			return i
+		}
+	}
+	return slices.Index(mask, true)
+}
+
+func TestEnvBigMoneyBeatsRandom(t *testing.T) {
+	e, err := NewEnv(Rulesets[0].Name, []string{"random"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for seed := range int64(3) {
+		if reward := playEnv(t, e, seed, bigMoneyPolicy); reward != 1 {
+			t.Errorf("seed %d: reward %v, want 1 for a win", seed, reward)
+		}
+	}
+}
+
+func TestEnvIdleLosesToBigMoney(t *testing.T) {
+	e, err := NewEnv(Rulesets[0].Name, []string{"bigmoney"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reward := playEnv(t, e, 1, idlePolicy); reward != 0 {
+		t.Errorf("reward %v, want 0 for a loss", reward)
+	}
+}
+
+func TestEnvIllegalAction(t *testing.T) {
+	e, err := NewEnv(Rulesets[0].Name, []string{"bigmoney"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := e.Step(EnvEndPhase); err == nil {
+		t.Error("stepping before a reset should fail")
+	}
+	if _, err := e.Reset(1, KingdomPresets[0].Name); err != nil {
+		t.Fatal(err)
+	}
+	mask := e.Mask()
+	for _, i := range []int{-1, EnvActionCount, EnvActionCount - 1, slices.Index(mask, false)} {
+		if _, _, _, err := e.Step(i); err == nil {
+			t.Errorf("action %d should not be legal", i)
+		}
+	}
+}
+
+func TestNewEnvErrors(t *testing.T) {
+	for _, tc := range []struct {
+		rules     string
+		opponents []string
+	}{
+		{"nonsense", []string{"bigmoney"}},
+		{Rulesets[0].Name, nil},
+		{Rulesets[0].Name, []string{"bigmoney", "bigmoney", "bigmoney", "bigmoney", "bigmoney", "bigmoney"}},
+		{Rulesets[0].Name, []string{"nobody"}},
+	} {
+		if _, err := NewEnv(tc.rules, tc.opponents); err == nil {
+			t.Errorf("NewEnv(%q, %q) should fail", tc.rules, tc.opponents)
+		}
+	}
+}
+
+func TestEnvSpecActionNamesAreUnique(t *testing.T) {
+	_, resp := handleEnvRequest(nil, EnvRequest{Cmd: "spec"})
+	if len(resp.Actions) != EnvActionCount {
+		t.Fatalf("spec has %d actions, want %d", len(resp.Actions), EnvActionCount)
+	}
+	seen := map[string]int{}
+	for i, name := range resp.Actions {
+		// unused card slots are all unnamed
+		if name == "" {
+			continue
+		}
+		if j, ok := seen[name]; ok {
+			t.Errorf("actions %d and %d are both named %q", j, i, name)
+		}
+		seen[name] = i
+	}
+}
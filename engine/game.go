@@ -0,0 +1,503 @@
+// the game itself: its rules, the state each player keeps and the messages that change it. nothing
+// here draws, so bots, the sim and the tournament run without a window
+package engine
+
+import (
+	"cmp"
+	"log"
+	"maps"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zehongharryqu/kingdom-of-heaven/assets"
+)
+
+// game states
+const (
+	RoomName = iota
+	Lobby
+	Picking
+	Playing
+	Ended
+	Closed
+)
+
+// turn phases
+const (
+	WorkPhase     = "Work Phase"
+	BlessingPhase = "Blessing Phase"
+)
+
+// stats for the current player, for display
+type TurnStats struct {
+	Works, Blessings, Faith int
+}
+
+func (ts *TurnStats) reset() {
+	ts.Works = 1
+	ts.Blessings = 1
+	ts.Faith = 0
+}
+
+type Game struct {
+	// what state the game is in
+	State int
+	// for sending messages between players
+	Conn Transport
+	// the room we are in, which results are recorded under
+	Room string
+	// the local player's name
+	Name string
+	// all the players
+	Players map[string]*PlayerData
+	// the options the host picked for this room
+	Options RoomOptions
+	// the kingdom the host is picking
+	Picks KingdomPicks
+	// which player gets which turn
+	TurnModulus []string
+	// which turn are we on
+	Turn int
+	// which phase is this turn in
+	Phase string
+	// whether the local player currently needs to make a decision (other than normal work or blessing)
+	Decision int
+	// how many other decisions we are waiting for
+	OtherDecisions int
+	// the card script running on this client, if any
+	script *ScriptRun
+	// cards a script played that are to be played again, next first
+	replays []*Card
+	// players who blocked the current trial
+	unaffected []string
+	// the active player's stats
+	Stats TurnStats
+	// the kingdom piles
+	Kingdom *Kingdom
+	// our cards
+	MyCards *PlayerCards
+	// which cards are currently in play, to draw
+	InPlayWork, InPlayFaith []*Card
+	// list of actions that have occured, last 10 of which are drawn
+	ActionLog []string
+	// triggered abilities waiting for events
+	triggers []*Trigger
+	// how the game ended, once everyone has sent their glory
+	Result *GameResult
+	// where results are recorded
+	ResultsPath string
+	// what a bot plays, nil for people
+	strategy Strategy
+	// how long a bot waits before each action
+	botDelay time.Duration
+	// the seed the host started the game with, which plays the same game again given the same moves
+	Seed int64
+	// every shuffle and random choice we make: from our own seed until the game starts, and then from
+	// our seat's stream of the game's seed
+	RNG *rand.Rand
+}
+
+// a game waiting for the player to pick a room
+func NewGame() *Game {
+	return &Game{State: RoomName, Players: make(map[string]*PlayerData), Options: DefaultRoomOptions(), Phase: WorkPhase, Stats: TurnStats{Works: 1, Blessings: 1, Faith: 0}, Decision: -1}
+}
+
+// a player's stream of random choices in a game: the game's seed moved along by their seat, so
+// no two players shuffle alike
+func seatRNG(seed int64, seat int) *rand.Rand {
+	return rand.New(rand.NewSource(seed + int64(seat)))
+}
+
+// tell everyone the work phase ended (start blessing phase)
+func (g *Game) startBlessing() {
+	g.Conn.Send([]string{EndPhase})
+}
+
+// show everyone the faith cards you play from your hand
+func (g *Game) playFaith(cards ...*Card) {
+	payload := []string{PlayedFaith, g.Name}
+	for _, c := range cards {
+		i := slices.Index(g.MyCards.Hand, c)
+		g.MyCards.Hand = slices.Delete(g.MyCards.Hand, i, i+1)
+		payload = append(payload, c.Name)
+	}
+	g.Conn.Send(payload)
+}
+
+// local player actions on turn end (end blessing phase)
+func (g *Game) rest() {
+	// put hand and in play cards into discard
+	g.MyCards.Discard = slices.Concat(g.MyCards.Discard, g.InPlayWork, g.InPlayFaith, g.MyCards.Hand)
+	// tell everyone the blessing phase ended, before we say whether the new hand shuffled the discard
+	g.Conn.Send([]string{EndPhase})
+	// draw new hand
+	g.MyCards.Hand = nil
+	g.MyCards.Hand = g.draw(5, g.MyCards.Hand)
+}
+
+// draws n of our cards into dest and returns the result, telling everyone if our discard had to be
+// shuffled for them
+func (g *Game) draw(n int, dest []*Card) []*Card {
+	discarded := len(g.MyCards.Discard)
+	dest = g.MyCards.drawNCards(n, dest)
+	if discarded > 0 && len(g.MyCards.Discard) == 0 {
+		g.Conn.Send([]string{Shuffled, g.Name})
+	}
+	return dest
+}
+
+// plays a work card from hand
+func (g *Game) playWork(c *Card) {
+	// take it out of hand, it will be drawn in play
+	i := slices.Index(g.MyCards.Hand, c)
+	g.MyCards.Hand = slices.Delete(g.MyCards.Hand, i, i+1)
+	g.localCardEffect(c, "")
+}
+
+// returns true if the top card of the pile can be bought this turn
+func (g *Game) canBuy(p *Pile) bool {
+	c := p.Top()
+	return p.supply && c != nil && g.Stats.Blessings > 0 && g.Stats.Faith >= c.Cost
+}
+
+// buys the top card of the pile
+func (g *Game) buy(p *Pile) {
+	c := p.Top()
+	// gains to discard
+	g.MyCards.Discard = append(g.MyCards.Discard, c)
+	// tell everyone you bought it so all kingdoms can decrement their supply
+	g.Conn.Send([]string{Bought, g.Name, c.Name})
+}
+
+func (g *Game) GameDone() {
+	allCards := slices.Concat(g.MyCards.Deck, g.MyCards.Discard, g.MyCards.Hand)
+	var glory int
+	for _, c := range allCards {
+		glory += c.glory
+	}
+	g.Conn.Send([]string{Glory, g.Name, strconv.Itoa(glory)})
+	g.State = Ended
+}
+
+func (g *Game) ReceiveMessages() {
+	for {
+		if !g.handleMessage(g.Conn.Receive()) {
+			return
+		}
+	}
+}
+
+// updates the game from a message, returning false once we have left the room
+func (g *Game) handleMessage(message []string) bool {
+	switch message[0] {
+	case JoinedLobby:
+		pid, err := strconv.Atoi(message[2])
+		if err != nil {
+			log.Fatal(err)
+		}
+		g.Players[message[1]] = &PlayerData{Name: message[1], pid: pid, Ready: false}
+	case HasPacks:
+		if pd, ok := g.Players[message[1]]; ok {
+			pd.packs = append([]string{}, message[2:]...)
+		}
+	case LeftLobby:
+		if name := message[1]; name == g.Name {
+			// if we are leaving, close our producer and consumer
+			g.Conn.Close()
+			g.State = Closed
+			return false
+		} else {
+			// if someone else is leaving, remove them
+			delete(g.Players, name)
+		}
+	case ToggledReady:
+		g.Players[message[1]].toggleReady()
+	case SetOptions:
+		g.Options = decodeRoomOptions(message[1:])
+	case SetBot:
+		bs := decodeBotSettings(message[2:])
+		if pd, ok := g.Players[message[1]]; ok {
+			pd.Bot = &bs
+		}
+		// if it's us, play the new way
+		if message[1] == g.Name && g.strategy != nil {
+			g.strategy = bs.strategy()
+		}
+	case PickedKingdom:
+		g.Picks = decodeKingdomPicks(message[1:])
+	case SetSeed:
+		g.Seed, _ = strconv.ParseInt(message[1], 10, 64)
+		g.RNG = seatRNG(g.Seed, slices.Index(g.PlayerOrder(), g.Name))
+		g.ActionLog = append(g.ActionLog, "Game seed "+message[1])
+	case SetKingdom:
+		// generate local kingdom from message
+		cards := make([]*Card, len(message)-1)
+		for i, c := range message[1:] {
+			cards[i] = CardNameMap[c]
+		}
+		g.Kingdom = InitKingdom(cards, len(g.Players), RulesetNamed(g.Options.Rules))
+		// create deck and discard
+		g.MyCards = InitPlayerCards(g.RNG)
+		for _, pd := range g.Players {
+			pd.cards = slices.Clone(g.MyCards.Discard)
+		}
+		g.MyCards.Hand = g.MyCards.drawNCards(5, g.MyCards.Hand)
+		// the first player's turn has started
+		g.Players[g.TurnModulus[0]].turns = 1
+		g.State = Playing
+	case EndPhase:
+		switch g.Phase {
+		case WorkPhase:
+			// moving to blessing phase
+			g.Phase = BlessingPhase
+		case BlessingPhase:
+			// turn ended, and what the player had in play goes to their discard
+			g.endTriggers()
+			pd := g.Players[g.TurnModulus[g.Turn%len(g.Players)]]
+			pd.discard = slices.Concat(pd.discard, g.InPlayWork, g.InPlayFaith)
+			g.Turn++
+			g.Players[g.TurnModulus[g.Turn%len(g.Players)]].turns++
+			g.Phase = WorkPhase
+			g.InPlayFaith = nil
+			g.InPlayWork = nil
+			g.Stats.reset()
+		}
+	case PlayedFaith:
+		// write that the player played the cards
+		g.ActionLog = append(g.ActionLog, message[1]+" played "+strings.Join(message[2:], ", "))
+		// draw the cards in play and add their faith one at a time, so abilities can react to each
+		for _, name := range message[2:] {
+			c := CardNameMap[name]
+			g.InPlayFaith = append(g.InPlayFaith, c)
+			g.Stats.Faith += c.faith
+			g.emit(Event{kind: EventCardPlayed, player: message[1], c: c})
+		}
+	case Played:
+		// write that the player played the card
+		g.ActionLog = append(g.ActionLog, message[1]+" played "+message[2])
+		c := CardNameMap[message[2]]
+		var flag string
+		if len(message) > 3 {
+			flag = message[3]
+		}
+		// draw the card in play, unless it's there already and being played again
+		if flag != PlayedAgain {
+			g.InPlayWork = append(g.InPlayWork, c)
+		}
+		// decrement the player's works unless it was played for free
+		if flag == "" {
+			g.Stats.Works--
+		}
+		// add what the card gives
+		g.Stats.Works += c.works
+		g.Stats.Blessings += c.blessings
+		g.Stats.Faith += c.faith
+		// nobody has blocked a new trial yet
+		if slices.Contains(c.CardTypes, TrialType) {
+			g.unaffected = nil
+		}
+		// set up and fire abilities
+		g.registerTriggers(c, message[1])
+		g.emit(Event{kind: EventCardPlayed, player: message[1], c: c})
+		// if you are not this player, react
+		if g.Name != message[1] {
+			g.reactToCard(CardNameMap[message[2]])
+		}
+	case AffectOthers:
+		// everyone else applies the effect to themselves
+		if g.Name != message[1] {
+			g.OtherDecisions += len(g.Players) - 1
+			g.affectedByCard(CardNameMap[message[2]])
+		}
+	case Affected:
+		// say how many cards the player drew, if any
+		if len(message) > 3 && message[3] != "0" {
+			msg := message[1] + " drew " + message[3] + " card"
+			if message[3] != "1" {
+				msg += "s"
+			}
+			g.ActionLog = append(g.ActionLog, msg)
+		}
+		g.otherDecided()
+	case Revealed:
+		if len(message) > 2 {
+			g.ActionLog = append(g.ActionLog, message[1]+" revealed "+strings.Join(message[2:], ", "))
+		} else {
+			g.ActionLog = append(g.ActionLog, message[1]+" revealed no cards")
+		}
+	case AddedStats:
+		n, _ := strconv.Atoi(message[3])
+		switch message[2] {
+		case "works":
+			g.Stats.Works += n
+		case "blessings":
+			g.Stats.Blessings += n
+		case "faith":
+			g.Stats.Faith += n
+		}
+		g.ActionLog = append(g.ActionLog, message[1]+" got +"+message[3]+" "+strings.ToUpper(message[2][:1])+message[2][1:])
+	case Distributed:
+		// give out cards from the pile in order until it runs out
+		c := CardNameMap[message[2]]
+		var got, missed []string
+		for _, name := range message[3:] {
+			p := g.Kingdom.PileWithTop(c.Name)
+			if p == nil {
+				missed = append(missed, name)
+				continue
+			}
+			p.take()
+			got = append(got, name)
+			g.Players[name].cards = append(g.Players[name].cards, c)
+			g.Players[name].discard = append(g.Players[name].discard, c)
+			if name == g.Name {
+				g.MyCards.Discard = append(g.MyCards.Discard, c)
+			}
+			g.emit(Event{kind: EventCardGained, player: name, c: c})
+		}
+		var msg string
+		if len(got) > 0 {
+			msg = strings.Join(got, ", ") + " gained " + c.Name
+		}
+		if len(missed) > 0 {
+			if msg != "" {
+				msg += "; "
+			}
+			msg += strings.Join(missed, ", ") + " got none, the pile is empty"
+		}
+		if msg != "" {
+			g.ActionLog = append(g.ActionLog, msg)
+		}
+	case Released:
+		// write that the player released the cards
+		g.ActionLog = append(g.ActionLog, message[1]+" released "+strings.Join(message[2:], ", "))
+		// add them to the released pile
+		for _, c := range message[2:] {
+			g.Kingdom.Released = append(g.Kingdom.Released, CardNameMap[c])
+			g.Players[message[1]].release(CardNameMap[c])
+		}
+	case Discarded:
+		// write what the player discarded
+		g.ActionLog = append(g.ActionLog, message[1]+" discarded "+strings.Join(message[2:], ", "))
+		for _, c := range message[2:] {
+			g.Players[message[1]].discard = append(g.Players[message[1]].discard, CardNameMap[c])
+		}
+	case Shuffled:
+		// the player's discard went into their deck, or their hand if they drew it all
+		g.ActionLog = append(g.ActionLog, message[1]+" shuffled")
+		g.Players[message[1]].discard = nil
+	case Gained:
+		// write that the player gained the card
+		g.ActionLog = append(g.ActionLog, message[1]+" gained "+message[2])
+		// remove a card from supply, which we did when we gained it
+		if message[1] != g.Name {
+			g.Kingdom.RemoveCard(message[2])
+		}
+		// most gains go to the discard, and we can't tell which don't
+		g.Players[message[1]].cards = append(g.Players[message[1]].cards, CardNameMap[message[2]])
+		g.Players[message[1]].discard = append(g.Players[message[1]].discard, CardNameMap[message[2]])
+		g.emit(Event{kind: EventCardGained, player: message[1], c: CardNameMap[message[2]]})
+	case Bought:
+		// write that the player gained the card
+		g.ActionLog = append(g.ActionLog, message[1]+" gained "+message[2])
+		// remove a card from supply
+		g.Kingdom.RemoveCard(message[2])
+		g.Players[message[1]].cards = append(g.Players[message[1]].cards, CardNameMap[message[2]])
+		g.Players[message[1]].discard = append(g.Players[message[1]].discard, CardNameMap[message[2]])
+		// decrement the player's blessings and faith
+		g.Stats.Blessings--
+		g.Stats.Faith -= CardNameMap[message[2]].Cost
+		g.emit(Event{kind: EventCardGained, player: message[1], c: CardNameMap[message[2]]})
+	case Glory:
+		// update the player's data with their final glory
+		glory, _ := strconv.Atoi(message[2])
+		g.Players[message[1]].setGlory(glory)
+		// once everyone has, work out who won
+		if g.Result == nil {
+			if g.Result = g.gameResult(); g.Result != nil && g.ResultsPath != "" {
+				if err := g.Result.record(g.ResultsPath); err != nil {
+					log.Println("recording result:", err)
+				}
+			}
+		}
+	case CardSpecific:
+		switch message[2] {
+		case Shield.Name:
+			// the player is unaffected by the trial
+			g.ActionLog = append(g.ActionLog, message[1]+" revealed Shield against "+message[3])
+			g.unaffected = append(g.unaffected, message[1])
+			g.otherDecided()
+		}
+	}
+	return true
+}
+
+// moves on to picking the kingdom once there are at least two players, all ready and with the same packs
+func (g *Game) StartIfReady() {
+	if len(g.Players) < 2 {
+		return
+	}
+	for _, playerData := range g.Players {
+		// nobody can start until everyone has the same packs as us
+		if !playerData.Ready || !playerData.SamePacks(PackChecksums()) {
+			return
+		}
+	}
+	g.State = Picking
+	// create turn order by pid
+	names := g.PlayerOrder()
+	// the first player picks the kingdom, starting from a random one
+	if names[0] == g.Name {
+		g.SendPicks(KingdomPicks{Picked: PickKingdom(g.Options, nil, g.RNG)})
+	}
+	g.TurnModulus = names
+}
+
+// returns true if it's our turn and we aren't waiting on anyone
+func (g *Game) CanAct() bool {
+	return g.TurnModulus[g.Turn%len(g.Players)] == g.Name && g.OtherDecisions == 0 && g.Decision == -1
+}
+
+// ends the phase if there is nothing left to do in it, returning true if it did
+func (g *Game) EndPhaseIfDone() bool {
+	switch {
+	case g.Phase == WorkPhase && (g.Stats.Works == 0 || !g.MyCards.HasWorks()):
+		log.Println(g.Name + " has no works, starting blessing")
+		g.startBlessing()
+	case g.Phase == BlessingPhase && g.Stats.Blessings == 0:
+		log.Println(g.Name + " has no blessings, ending turn")
+		g.rest()
+	default:
+		return false
+	}
+	return true
+}
+
+// returns the player names in turn order, by pid and then name so every client agrees
+func (g *Game) PlayerOrder() []string {
+	names := slices.Collect(maps.Keys(g.Players))
+	slices.SortFunc(names, func(a, b string) int {
+		return cmp.Or(
+			cmp.Compare(g.Players[a].pid, g.Players[b].pid),
+			cmp.Compare(a, b),
+		)
+	})
+	return names
+}
+
+// loads and registers the card packs in dir, stopping if any is invalid
+func LoadPacks(dir string) {
+	packs, err := assets.LoadPacks(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, p := range packs {
+		if err := registerPack(p); err != nil {
+			log.Fatal(err)
+		}
+		log.Println("loaded pack " + p.Name + " " + p.Version)
+	}
+}
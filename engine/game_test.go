@@ -0,0 +1,30 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+// the first shuffle of the starting cards for a seed and seat, S for Study and P for Parable, bottom
+// first. these only change if the shuffles do, which would stop recorded games playing back
+func TestSeatRNGShufflesTheSame(t *testing.T) {
+	for _, tc := range []struct {
+		seed int64
+		seat int
+		deck string
+	}{
+		{42, 0, "SSPPSPSSSS"},
+		{42, 1, "PSSPPSSSSS"},
+		{7, 2, "SSPSSPSSPS"},
+	} {
+		pc := InitPlayerCards(seatRNG(tc.seed, tc.seat))
+		pc.fillDeck(10)
+		var deck strings.Builder
+		for _, c := range pc.Deck {
+			deck.WriteString(c.Name[:1])
+		}
+		if deck.String() != tc.deck {
+			t.Errorf("seed %d seat %d shuffled %s, want %s", tc.seed, tc.seat, deck.String(), tc.deck)
+		}
+	}
+}
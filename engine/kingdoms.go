@@ -0,0 +1,262 @@
+// choosing which verses are in the kingdom
+package engine
+
+import (
+	"log"
+	"maps"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// how many verses are in a kingdom
+const KingdomSize = 10
+
+// the sets kingdom cards come from, in the order they first appear in the card data file
+var CardSets = cardSets()
+
+func cardSets() []string {
+	var sets []string
+	for _, c := range NonBaseCards {
+		if !slices.Contains(sets, c.set) {
+			sets = append(sets, c.set)
+		}
+	}
+	return sets
+}
+
+// how many random kingdoms to try when looking for one that meets the constraints
+const KingdomTries = 500
+
+// tags the host can ask for a minimum of
+var ConstraintTags = []string{"village", "draw"}
+
+// options for the room, picked by the host in the lobby
+type RoomOptions struct {
+	// which sets the kingdom is picked from
+	Sets []string
+	// how many cards to pick from each set at least
+	MinPerSet int
+	// what a random kingdom should look like
+	Constraints KingdomConstraints
+	// the name of the ruleset
+	Rules string
+	// whether the game counts for ratings, so no one can have the advisor's help
+	Ranked bool
+}
+
+// what a random kingdom should look like
+type KingdomConstraints struct {
+	// how many different costs it needs at least
+	MinCosts int
+	// how many cards with each tag it needs at least
+	MinTags map[string]int
+	// whether it needs a Reaction if it has any Trials
+	ReactionWithTrials bool
+	// the most Trials it can have, or -1 for any number
+	MaxTrials int
+}
+
+func DefaultRoomOptions() RoomOptions {
+	return RoomOptions{Sets: slices.Clone(CardSets), Constraints: KingdomConstraints{MinTags: map[string]int{}, MaxTrials: -1}, Rules: Rulesets[0].Name}
+}
+
+// turns the options into message fields of the form key=value
+func (o *RoomOptions) Encode() []string {
+	var tags []string
+	for _, tag := range slices.Sorted(maps.Keys(o.Constraints.MinTags)) {
+		tags = append(tags, tag+":"+strconv.Itoa(o.Constraints.MinTags[tag]))
+	}
+	reaction := "0"
+	if o.Constraints.ReactionWithTrials {
+		reaction = "1"
+	}
+	ranked := "0"
+	if o.Ranked {
+		ranked = "1"
+	}
+	return []string{
+		"sets=" + strings.Join(o.Sets, ","),
+		"min=" + strconv.Itoa(o.MinPerSet),
+		"costs=" + strconv.Itoa(o.Constraints.MinCosts),
+		"tags=" + strings.Join(tags, ","),
+		"reaction=" + reaction,
+		"trials=" + strconv.Itoa(o.Constraints.MaxTrials),
+		"rules=" + o.Rules,
+		"ranked=" + ranked,
+	}
+}
+
+// reads options from message fields of the form key=value, ignoring keys it doesn't know
+func decodeRoomOptions(fields []string) RoomOptions {
+	o := RoomOptions{Constraints: KingdomConstraints{MinTags: map[string]int{}, MaxTrials: -1}, Rules: Rulesets[0].Name}
+	for _, field := range fields {
+		key, value, _ := strings.Cut(field, "=")
+		switch key {
+		case "sets":
+			if value != "" {
+				o.Sets = strings.Split(value, ",")
+			}
+		case "min":
+			o.MinPerSet, _ = strconv.Atoi(value)
+		case "costs":
+			o.Constraints.MinCosts, _ = strconv.Atoi(value)
+		case "tags":
+			for _, tagMin := range strings.Split(value, ",") {
+				if tag, n, ok := strings.Cut(tagMin, ":"); ok {
+					o.Constraints.MinTags[tag], _ = strconv.Atoi(n)
+				}
+			}
+		case "reaction":
+			o.Constraints.ReactionWithTrials = value == "1"
+		case "trials":
+			o.Constraints.MaxTrials, _ = strconv.Atoi(value)
+		case "rules":
+			o.Rules = value
+		case "ranked":
+			o.Ranked = value == "1"
+		}
+	}
+	return o
+}
+
+// returns descriptions of the constraints the kingdom doesn't meet
+func (kc *KingdomConstraints) Unmet(kingdom []*Card) []string {
+	var unmet []string
+	costs := make(map[int]bool)
+	trials, reactions := 0, 0
+	for _, c := range kingdom {
+		costs[c.Cost] = true
+		if slices.Contains(c.CardTypes, TrialType) {
+			trials++
+		}
+		if slices.Contains(c.CardTypes, ReactionType) {
+			reactions++
+		}
+	}
+	if len(costs) < kc.MinCosts {
+		unmet = append(unmet, "needs "+strconv.Itoa(kc.MinCosts)+" different costs")
+	}
+	for _, tag := range slices.Sorted(maps.Keys(kc.MinTags)) {
+		n, _ := where(kingdom, func(c *Card) bool { return slices.Contains(c.tags, tag) })
+		if len(n) < kc.MinTags[tag] {
+			unmet = append(unmet, "needs "+strconv.Itoa(kc.MinTags[tag])+" "+tag)
+		}
+	}
+	if kc.ReactionWithTrials && trials > 0 && reactions == 0 {
+		unmet = append(unmet, "needs a Reaction with Trials")
+	}
+	if kc.MaxTrials != -1 && trials > kc.MaxTrials {
+		unmet = append(unmet, "needs at most "+strconv.Itoa(kc.MaxTrials)+" Trials")
+	}
+	return unmet
+}
+
+// describes the constraints for display
+func (kc *KingdomConstraints) String() string {
+	var parts []string
+	if kc.MinCosts > 0 {
+		parts = append(parts, strconv.Itoa(kc.MinCosts)+"+ costs")
+	}
+	for _, tag := range slices.Sorted(maps.Keys(kc.MinTags)) {
+		if kc.MinTags[tag] > 0 {
+			parts = append(parts, strconv.Itoa(kc.MinTags[tag])+"+ "+tag)
+		}
+	}
+	if kc.ReactionWithTrials {
+		parts = append(parts, "Reaction with Trials")
+	}
+	if kc.MaxTrials != -1 {
+		parts = append(parts, "at most "+strconv.Itoa(kc.MaxTrials)+" Trials")
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describes the chosen sets for display
+func (o *RoomOptions) SetsLabel() string {
+	if len(o.Sets) == 0 {
+		return "no sets"
+	}
+	return strings.Join(o.Sets, ", ")
+}
+
+// a named kingdom the host can load
+type KingdomPreset struct {
+	Name    string
+	Kingdom []*Card
+}
+
+var KingdomPresets = []KingdomPreset{
+	{"First Game", []*Card{Purification, Shield, Bethlehem, Gift, LostCoin, Industry, Transform, Craft, Festival, Merchant}},
+	{"Trial-heavy", []*Card{Shield, Bethlehem, Doubt, Decree, Industry, Wisdom, Stumble, Desires, Feed5000, Plan}},
+	{"Engine Builder", []*Card{NewCreation, Belief, Bethlehem, Duplication, Transform, Collection, Craft, Festival, Merchant, Plan}},
+}
+
+// returns the cards in the chosen sets that aren't banned, which the kingdom is picked from. a split or
+// mixed pile is one verse, so only its first card is in the pool
+func kingdomPool(o RoomOptions, banned []*Card) []*Card {
+	var pool []*Card
+	for _, c := range NonBaseCards {
+		if slices.Contains(o.Sets, c.set) && !slices.Contains(banned, c) && !slices.ContainsFunc(pool, func(p *Card) bool { return p.pileName() == c.pileName() }) {
+			pool = append(pool, c)
+		}
+	}
+	return pool
+}
+
+// picks a random kingdom meeting the constraints with rng. if none
+// of the tries meets them all, the one missing the fewest is used
+func PickKingdom(o RoomOptions, banned []*Card, rng *rand.Rand) []*Card {
+	var best []*Card
+	bestUnmet := -1
+	for range KingdomTries {
+		kingdom := randomKingdom(o, banned, rng)
+		unmet := len(o.Constraints.Unmet(kingdom))
+		if bestUnmet == -1 || unmet < bestUnmet {
+			best, bestUnmet = kingdom, unmet
+		}
+		if unmet == 0 {
+			break
+		}
+	}
+	if bestUnmet > 0 {
+		log.Println("no kingdom meets all the constraints, using one missing " + strconv.Itoa(bestUnmet))
+	}
+	return best
+}
+
+// picks a random kingdom from the pool, with at least minPerSet from each set where possible
+func randomKingdom(o RoomOptions, banned []*Card, rng *rand.Rand) []*Card {
+	pool := kingdomPool(o, banned)
+	rng.Shuffle(len(pool), func(i, j int) {
+		pool[i], pool[j] = pool[j], pool[i]
+	})
+	var kingdom []*Card
+	// take the minimum from each set first
+	for _, set := range o.Sets {
+		taken := 0
+		for _, c := range pool {
+			if taken == o.MinPerSet || len(kingdom) == KingdomSize {
+				break
+			}
+			if c.set == set {
+				kingdom = append(kingdom, c)
+				taken++
+			}
+		}
+	}
+	// then fill up with the rest
+	for _, c := range pool {
+		if len(kingdom) == KingdomSize {
+			break
+		}
+		if !slices.Contains(kingdom, c) {
+			kingdom = append(kingdom, c)
+		}
+	}
+	return kingdom
+}
@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"math/rand"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestPickKingdomSeeded(t *testing.T) {
+	want := []string{"Purification", "Decree", "LostCoin", "Inspiration", "Collection", "GrowFaith", "Depletion", "Craft", "Duplication", "Transform"}
+	for range 2 {
+		if got := CardNames(PickKingdom(DefaultRoomOptions(), nil, rand.New(rand.NewSource(7)))); !slices.Equal(got, want) {
+			t.Errorf("seed 7 picked %v, want %v", got, want)
+		}
+	}
+}
+
+// adds n verses in a set of their own until the test ends
+func addTestSet(t *testing.T, set string, n int) []*Card {
+	t.Helper()
+	nonBase := NonBaseCards
+	t.Cleanup(func() { NonBaseCards = nonBase })
+	NonBaseCards = slices.Clone(NonBaseCards)
+	var cards []*Card
+	for i := range n {
+		c := &Card{Name: set + strconv.Itoa(i), Cost: i, CardTypes: []int{WorkType}, set: set}
+		cards = append(cards, c)
+		NonBaseCards = append(NonBaseCards, c)
+	}
+	return cards
+}
+
+func TestKingdomFromChosenSets(t *testing.T) {
+	extra := addTestSet(t, "extra", 4)
+	o := DefaultRoomOptions()
+	o.Sets = []string{"extra"}
+	// there aren't enough verses for a whole kingdom, so it has all there are
+	got := PickKingdom(o, extra[:1], rand.New(rand.NewSource(1)))
+	slices.SortFunc(got, func(a, b *Card) int { return a.Cost - b.Cost })
+	if !slices.Equal(got, extra[1:]) {
+		t.Errorf("kingdom from extra without %s is %v", extra[0].Name, CardNames(got))
+	}
+	o.Sets = []string{"base", "extra"}
+	o.MinPerSet = 3
+	for seed := range int64(20) {
+		kingdom := PickKingdom(o, nil, rand.New(rand.NewSource(seed)))
+		if n, _ := where(kingdom, func(c *Card) bool { return c.set == "extra" }); len(kingdom) != KingdomSize || len(n) < 3 {
+			t.Errorf("seed %d: %v has %d extra verses, want at least 3", seed, CardNames(kingdom), len(n))
+		}
+	}
+}
+
+func TestRoomOptionsRoundTrip(t *testing.T) {
+	o := RoomOptions{
+		Sets:        []string{"base", "extra"},
+		MinPerSet:   2,
+		Constraints: KingdomConstraints{MinCosts: 4, MinTags: map[string]int{"draw": 1, "village": 2}, ReactionWithTrials: true, MaxTrials: 2},
+		Rules:       "quick game",
+		Ranked:      true,
+	}
+	if got := decodeRoomOptions(o.Encode()); !reflect.DeepEqual(got, o) {
+		t.Errorf("decoded %+v, want %+v", got, o)
+	}
+	if got := decodeRoomOptions([]string{"colour=blue"}); !reflect.DeepEqual(got, RoomOptions{Constraints: KingdomConstraints{MinTags: map[string]int{}, MaxTrials: -1}, Rules: Rulesets[0].Name}) {
+		t.Errorf("unknown keys decoded to %+v", got)
+	}
+	if o.SetsLabel() != "base, extra" || (&RoomOptions{}).SetsLabel() != "no sets" {
+		t.Errorf("sets labelled %q", o.SetsLabel())
+	}
+}
+
+func TestUnmet(t *testing.T) {
+	kc := KingdomConstraints{MinCosts: 4, MinTags: map[string]int{"village": 1, "draw": 2}, ReactionWithTrials: true, MaxTrials: 1}
+	for _, tc := range []struct {
+		kingdom []*Card
+		want    []string
+	}{
+		{[]*Card{Festival, Craft, Industry, Shield, Gift}, nil},
+		{[]*Card{Festival, Craft, Industry}, []string{"needs 4 different costs"}},
+		{[]*Card{Purification, Gift, Industry, Festival}, []string{"needs 2 draw"}},
+		{[]*Card{Purification, Gift, Doubt, Craft, Industry, Festival}, []string{"needs a Reaction with Trials"}},
+		{[]*Card{Shield, Gift, Doubt, Decree, Industry, Festival}, []string{"needs at most 1 Trials"}},
+	} {
+		if got := kc.Unmet(tc.kingdom); !slices.Equal(got, tc.want) {
+			t.Errorf("%v: unmet %q, want %q", CardNames(tc.kingdom), got, tc.want)
+		}
+	}
+}
+
+func TestPickKingdomMeetsConstraints(t *testing.T) {
+	o := DefaultRoomOptions()
+	o.Constraints = KingdomConstraints{MinCosts: 4, MinTags: map[string]int{"village": 2, "draw": 2}, ReactionWithTrials: true, MaxTrials: 1}
+	for seed := range int64(20) {
+		if kingdom := PickKingdom(o, nil, rand.New(rand.NewSource(seed))); len(o.Constraints.Unmet(kingdom)) > 0 {
+			t.Errorf("seed %d: %v %q", seed, CardNames(kingdom), o.Constraints.Unmet(kingdom))
+		}
+	}
+	// when nothing can meet them, it still picks a whole kingdom
+	o.Constraints = KingdomConstraints{MinTags: map[string]int{"village": KingdomSize + 1}, MaxTrials: -1}
+	if kingdom := PickKingdom(o, nil, rand.New(rand.NewSource(1))); len(kingdom) != KingdomSize {
+		t.Errorf("picked %d cards with impossible constraints", len(kingdom))
+	}
+}
+
+func TestConstraintsString(t *testing.T) {
+	kc := KingdomConstraints{MinCosts: 3, MinTags: map[string]int{"village": 1, "draw": 0}, ReactionWithTrials: true, MaxTrials: 2}
+	if got, want := kc.String(), "3+ costs, 1+ village, Reaction with Trials, at most 2 Trials"; got != want {
+		t.Errorf("%q, want %q", got, want)
+	}
+	if got := (&KingdomConstraints{MaxTrials: -1}).String(); got != "none" {
+		t.Errorf("no constraints described as %q", got)
+	}
+}
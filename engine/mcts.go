@@ -0,0 +1,365 @@
+// a bot that searches ahead with determinized Monte Carlo tree search: it guesses what it can't see
+// (the other players' hands and everyone's deck order) from what it has seen, plays the game out from
+// there many times, and keeps a tree of its own moves with how often each led to a win
+package engine
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	// how many games the search plays out for each decision by default, and how long bots can take
+	MCTSIterations = 1000
+	MCTSBudget     = 2 * time.Second
+	// how much the search tries moves it hasn't tried much, over ones that have won
+	UCTExplore = math.Sqrt2
+	// how much the search favours the move the rollout strategy would make, at first. the bonus shrinks
+	// as the move is tried, so play outs can still overrule it; less than this and a few hundred play
+	// outs are too noisy to beat the rollout strategy playing alone
+	MCTSPriorWeight = 5
+	// how many cards the other players are guessed to have in hand
+	GuessHandSize = 5
+	// how much of a play out's score is from winning, the rest being from the glory lead, so moves can
+	// be told apart when they all win or all lose. a lead of MCTSGloryScale is worth most of the rest
+	MCTSWinWeight  = 0.75
+	MCTSGloryScale = 10
+)
+
+// searches every decision. the budget is an iteration count, a duration or both, whichever runs out
+// first; a duration of 0 means no time limit. only an iteration budget plays the same with the same seed
+type MCTS struct {
+	iterations int
+	budget     time.Duration
+	// plays out the games, for everyone, once the search leaves the tree
+	rollout Strategy
+}
+
+// one of our moves in the tree, reached by the moves above it whatever the other players did
+type MCTSNode struct {
+	move     Action
+	children []*MCTSNode
+	// how many times the move was picked, how many times it could have been, and the total score of
+	// the play outs through it
+	visits, avail int
+	wins          float64
+}
+
+// a guess at the whole game, one Game per player in a local room
+type World struct {
+	games []*Game
+	conns []*LocalClient
+	// which of the games is the searching player's
+	me int
+}
+
+func (s *MCTS) act(v View) Action {
+	return s.search(v.g, actions(v))
+}
+
+func (s *MCTS) decide(v View, choices []*Card, skippable bool) *Card {
+	return s.search(v.g, decisionMoves(choices, skippable)).Card
+}
+
+func (s *MCTS) String() string {
+	if s.budget == 0 {
+		return "mcts:" + strconv.Itoa(s.iterations)
+	}
+	if s.iterations == math.MaxInt {
+		return "mcts:" + s.budget.String()
+	}
+	return "mcts"
+}
+
+// the legal moves worth searching on our turn
+func actions(v View) []Action {
+	legal := v.moves()
+	// faith is only for buying, so all of it is always played before anything is bought
+	if slices.Contains(legal, Action{Kind: ActionPlayFaith}) {
+		return []Action{{Kind: ActionPlayFaith}}
+	}
+	moves := []Action{{Kind: ActionEndPhase}}
+	for _, a := range legal {
+		// nothing is worth gaining only to lose glory
+		if a.Kind == ActionPlayWork || a.Kind == ActionBuy && a.Card.glory >= 0 {
+			moves = append(moves, a)
+		}
+	}
+	return moves
+}
+
+// the picks we could make for the current decision
+func decisionMoves(choices []*Card, skippable bool) []Action {
+	var moves []Action
+	for _, c := range choices {
+		moves = append(moves, Action{Kind: ActionChoose, Card: c})
+	}
+	if skippable || len(moves) == 0 {
+		moves = append(moves, Action{Kind: ActionSkip})
+	}
+	return moves
+}
+
+// plays out games from guesses at the current state and returns the move that was tried the most,
+// the one that scored better if two were tried as often
+func (s *MCTS) search(g *Game, moves []Action) Action {
+	if len(moves) == 1 {
+		return moves[0]
+	}
+	// the search draws from its own rng, seeded once from the game's, so however many play outs a time
+	// budget allows the game's rng is in the same place after the decision
+	rng := rand.New(rand.NewSource(View{g}.random().Int63()))
+	root := &MCTSNode{}
+	start := time.Now()
+	for i := 0; i < s.iterations && (s.budget == 0 || time.Since(start) < s.budget); i++ {
+		w := s.determinize(g, rng)
+		t := &TreeStrategy{s: s, rng: rng, node: root, path: []*MCTSNode{root}}
+		w.games[w.me].strategy = t
+		won := w.playOut()
+		for _, n := range t.path {
+			n.visits++
+			n.wins += won
+		}
+	}
+	var best *MCTSNode
+	for _, n := range root.children {
+		if slices.Contains(moves, n.move) && (best == nil || n.visits > best.visits || n.visits == best.visits && n.wins > best.wins) {
+			best = n
+		}
+	}
+	if best == nil {
+		return moves[0]
+	}
+	return best.move
+}
+
+// guesses the whole game from what g has seen. everyone gets a copy of the public state; we keep our
+// own cards and the cards we put on our deck, but not the order of the rest of it, and the others are
+// dealt the cards we have seen them get
+func (s *MCTS) determinize(g *Game, rng *rand.Rand) *World {
+	room := NewLocalRoom()
+	w := &World{}
+	active := g.TurnModulus[g.Turn%len(g.Players)]
+	// if we are reacting to someone's card, everyone waits for us alone
+	reacting := g.script != nil && g.script.section != SectionPlay
+	for i, name := range g.TurnModulus {
+		c := g.clone()
+		c.RNG = rand.New(rand.NewSource(rng.Int63()))
+		c.strategy = s.rollout
+		c.Conn = room.connect()
+		c.OtherDecisions = 0
+		if reacting {
+			c.OtherDecisions = 1
+		}
+		if name == g.Name {
+			w.me = i
+			c.MyCards.rng = c.RNG
+			c.MyCards.shuffleUnknown(c.RNG)
+		} else {
+			c.Name = name
+			c.MyCards = guessCards(g, name, c.RNG)
+			c.Decision = -1
+			c.script = nil
+			if reacting && name == active {
+				c.script = waitingScript(g)
+			}
+		}
+		w.games = append(w.games, c)
+		w.conns = append(w.conns, c.Conn.(*LocalClient))
+	}
+	return w
+}
+
+// copies the game state a search needs, so playing on doesn't change g
+func (g *Game) clone() *Game {
+	c := &Game{
+		State:          g.State,
+		Name:           g.Name,
+		Players:        make(map[string]*PlayerData, len(g.Players)),
+		Options:        g.Options,
+		TurnModulus:    g.TurnModulus,
+		Turn:           g.Turn,
+		Phase:          g.Phase,
+		Decision:       g.Decision,
+		OtherDecisions: g.OtherDecisions,
+		unaffected:     slices.Clone(g.unaffected),
+		Stats:          g.Stats,
+		Kingdom:        g.Kingdom.clone(),
+		MyCards:        g.MyCards.clone(),
+		InPlayWork:     slices.Clone(g.InPlayWork),
+		InPlayFaith:    slices.Clone(g.InPlayFaith),
+		triggers:       slices.Clone(g.triggers),
+		replays:        slices.Clone(g.replays),
+	}
+	for name, pd := range g.Players {
+		pdc := *pd
+		pdc.cards = slices.Clone(pd.cards)
+		pdc.discard = slices.Clone(pd.discard)
+		c.Players[name] = &pdc
+	}
+	if g.script != nil {
+		c.script = g.script.clone()
+	}
+	return c
+}
+
+// deals another player the cards we have seen them get that aren't in play: the ones we saw go to
+// their discard stay there, and of the rest they get a hand and their deck
+func guessCards(g *Game, name string, rng *rand.Rand) *PlayerCards {
+	pd := g.Players[name]
+	cards := slices.Clone(pd.cards)
+	take := func(c *Card) bool {
+		i := slices.Index(cards, c)
+		if i != -1 {
+			cards = slices.Delete(cards, i, i+1)
+		}
+		return i != -1
+	}
+	if g.TurnModulus[g.Turn%len(g.Players)] == name {
+		for _, c := range slices.Concat(g.InPlayWork, g.InPlayFaith) {
+			take(c)
+		}
+	}
+	// a card we think is in their discard may have been released or gained somewhere else
+	var discard []*Card
+	for _, c := range pd.discard {
+		if take(c) {
+			discard = append(discard, c)
+		}
+	}
+	rng.Shuffle(len(cards), func(i, j int) {
+		cards[i], cards[j] = cards[j], cards[i]
+	})
+	n := min(GuessHandSize, len(cards))
+	pc := &PlayerCards{Hand: cards[:n:n], Deck: cards[n:], Discard: discard, rng: rng}
+	sortCards(pc.Hand)
+	return pc
+}
+
+// the active player's script, if the card they played last waits for the other players. it is at
+// the wait for everyone, though it doesn't know any cards it picked before it
+func waitingScript(g *Game) *ScriptRun {
+	if len(g.InPlayWork) == 0 {
+		return nil
+	}
+	c := g.InPlayWork[len(g.InPlayWork)-1]
+	if c.script == nil {
+		return nil
+	}
+	for pc, in := range c.script.sections[SectionPlay] {
+		if in.op == "wait" {
+			return &ScriptRun{c: c, section: SectionPlay, pc: pc, vars: make(map[string]ScriptVar), waiting: true}
+		}
+	}
+	return nil
+}
+
+// plays the guess to the end, one message at a time like a sim, and scores it for us between 0 and 1
+// from our share of the win and our glory lead. games that go on too long score 0
+func (w *World) playOut() float64 {
+	for stepped := true; stepped; {
+		stepped = false
+		for i, g := range w.games {
+			if g.botStep(w.conns[i]) {
+				stepped = true
+			}
+		}
+		if w.games[w.me].Turn >= MaxSimRounds*len(w.games) {
+			return 0
+		}
+	}
+	me := w.games[w.me]
+	if me.Result == nil {
+		return 0
+	}
+	var winners []string
+	glory, best := 0, math.MinInt
+	for _, p := range me.Result.Players {
+		if p.Place == 1 {
+			winners = append(winners, p.Name)
+		}
+		if p.Name == me.Name {
+			glory = p.Glory
+		} else {
+			best = max(best, p.Glory)
+		}
+	}
+	var share float64
+	if slices.Contains(winners, me.Name) {
+		share = 1 / float64(len(winners))
+	}
+	lead := (1 + math.Tanh(float64(glory-best)/MCTSGloryScale)) / 2
+	return MCTSWinWeight*share + (1-MCTSWinWeight)*lead
+}
+
+// plays the searching player in a guess: picks moves down the tree, adds one new move, and then plays
+// the rollout strategy
+type TreeStrategy struct {
+	s   *MCTS
+	rng *rand.Rand
+	// where we are in the tree, or nil once we have left it
+	node *MCTSNode
+	// the nodes this play out went through, to count its result in
+	path []*MCTSNode
+}
+
+func (t *TreeStrategy) act(v View) Action {
+	return t.pick(actions(v), func() Action { return t.s.rollout.act(v) })
+}
+
+func (t *TreeStrategy) decide(v View, choices []*Card, skippable bool) *Card {
+	return t.pick(decisionMoves(choices, skippable), func() Action {
+		return Action{Kind: ActionChoose, Card: t.s.rollout.decide(v, choices, skippable)}
+	}).Card
+}
+
+func (t *TreeStrategy) String() string {
+	return "tree"
+}
+
+// picks the next move in the tree, or with the rollout strategy once out of it. the rollout
+// strategy's move is tried first and has a head start, so few play outs go on worse moves
+func (t *TreeStrategy) pick(moves []Action, rollout func() Action) Action {
+	if t.node == nil {
+		return rollout()
+	}
+	prior := rollout()
+	// add a move we haven't tried from here, then play on without the tree
+	var legal, untried []*MCTSNode
+	for _, m := range moves {
+		if i := slices.IndexFunc(t.node.children, func(n *MCTSNode) bool { return n.move == m }); i != -1 {
+			legal = append(legal, t.node.children[i])
+		} else {
+			untried = append(untried, &MCTSNode{move: m})
+		}
+	}
+	if len(untried) > 0 {
+		n := untried[t.rng.Intn(len(untried))]
+		if i := slices.IndexFunc(untried, func(n *MCTSNode) bool { return n.move == prior }); i != -1 {
+			n = untried[i]
+		}
+		t.node.children = append(t.node.children, n)
+		t.path = append(t.path, n)
+		t.node = nil
+		return n.move
+	}
+	// otherwise the move that has won most, allowing for how little it has been tried when it could have
+	var best *MCTSNode
+	var bestScore float64
+	for _, n := range legal {
+		n.avail++
+		score := n.wins/float64(n.visits) + UCTExplore*math.Sqrt(math.Log(float64(n.avail))/float64(n.visits))
+		if n.move == prior {
+			score += MCTSPriorWeight / float64(n.visits)
+		}
+		if best == nil || score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	t.path = append(t.path, best)
+	t.node = best
+	return best.move
+}
@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+// however long the search runs, it takes one draw from the game's rng
+func TestSearchDrawsOnceFromGame(t *testing.T) {
+	for _, s := range []*MCTS{{iterations: 5, rollout: BigMoney{}}, {iterations: 50, budget: time.Millisecond, rollout: BigMoney{}}} {
+		w := newTestWorld(t)
+		g := w.games[0]
+		setCards(g, []*Card{Festival, Study}, nil, nil)
+		if len(actions(View{g})) < 2 {
+			t.Fatal("there should be something to search")
+		}
+		g.RNG = rand.New(rand.NewSource(3))
+		s.act(View{g})
+		want := rand.New(rand.NewSource(3))
+		want.Int63()
+		if got, want := g.RNG.Int63(), want.Int63(); got != want {
+			t.Errorf("%s: game rng moved on to %d, want %d", s, got, want)
+		}
+	}
+}
+
+// cards we put on our deck stay where they are in every guess, and only the rest is shuffled
+func TestDeterminizeKeepsKnownDeckCards(t *testing.T) {
+	w := newTestWorld(t)
+	g := w.games[0]
+	setCards(g, nil, []*Card{Study, Study, Study, Parable, Parable}, nil)
+	g.MyCards.putOnDeck(Miracle, Prayer)
+	s := &MCTS{iterations: 1, rollout: BigMoney{}}
+	rng := rand.New(rand.NewSource(1))
+	for range 20 {
+		deck := s.determinize(g, rng).games[0].MyCards.Deck
+		if deck[5] != Miracle || deck[6] != Prayer {
+			t.Fatalf("guessed deck %v doesn't end Miracle, Prayer", CardNames(deck))
+		}
+	}
+	g.MyCards.Hand = g.MyCards.drawNCards(1, g.MyCards.Hand)
+	if g.MyCards.known != 1 {
+		t.Errorf("%d known cards after drawing one of two, want 1", g.MyCards.known)
+	}
+}
+
+// the other players keep the cards we saw go to their discard, and are dealt the rest
+func TestGuessCardsKeepsSeenDiscard(t *testing.T) {
+	w := newTestWorld(t)
+	playMove(t, w, Action{Kind: ActionEndPhase})
+	playMove(t, w, Action{Kind: ActionPlayFaith})
+	played := slices.Clone(w.games[0].InPlayFaith)
+	playMove(t, w, Action{Kind: ActionEndPhase})
+	g := w.games[1]
+	if got := g.Players["p1"].discard; !slices.Equal(got, played) {
+		t.Fatalf("p1's seen discard is %v, want %v", CardNames(got), CardNames(played))
+	}
+	pc := guessCards(g, "p1", rand.New(rand.NewSource(1)))
+	if !slices.Equal(pc.Discard, played) || len(pc.Hand) != GuessHandSize || len(pc.Deck) != 10-len(played)-GuessHandSize {
+		t.Errorf("guessed hand %d, deck %d and discard %v", len(pc.Hand), len(pc.Deck), CardNames(pc.Discard))
+	}
+	// once they shuffle, nothing is known to be in their discard
+	w.games[0].Conn.Send([]string{Shuffled, "p1"})
+	w.sync()
+	if pc := guessCards(g, "p1", rand.New(rand.NewSource(1))); len(pc.Discard) != 0 {
+		t.Errorf("guessed discard %v after a shuffle", CardNames(pc.Discard))
+	}
+}
+
+// at the default budget the search beats the strategy it plays out with
+func TestMCTSBeatsBigMoney(t *testing.T) {
+	if testing.Short() {
+		t.Skip("plays whole games with searches")
+	}
+	sc := &SimConfig{kingdom: KingdomPresets[0].Kingdom, rules: Rulesets[0], strategies: []Strategy{&MCTS{iterations: MCTSIterations, rollout: BigMoney{}}, BigMoney{}}}
+	var wins float64
+	const games = 4
+	for i := range games {
+		for _, p := range sc.play(int64(i), i%2).players {
+			if p.Name == "p1" && p.Place == 1 {
+				wins++
+			}
+		}
+	}
+	if wins <= games/2 {
+		t.Errorf("MCTS won %v of %d games against Big Money", wins, games)
+	}
+}
@@ -0,0 +1,82 @@
+// the legal moves in a game, shared by the UI, bots and the advisor so they all follow the same rules
+package engine
+
+import "slices"
+
+// every move we can make right now: the picks for our decision if we are making one, and otherwise on
+// our turn the work cards we can play, the faith cards, the cards we can buy and ending the phase.
+// there are none while we wait for someone else
+func (g *Game) LegalMoves() []Action {
+	if g.State != Playing || g.MyCards == nil || g.Kingdom == nil || g.Kingdom.GameDone() {
+		return nil
+	}
+	if g.Decision != -1 {
+		_, skippable := g.PromptDecision()
+		return decisionMoves(g.decisionChoices(), skippable)
+	}
+	if !g.CanAct() {
+		return nil
+	}
+	var moves []Action
+	// each card in hand is one move however many copies there are
+	var hand []*Card
+	for _, c := range g.MyCards.Hand {
+		if !slices.Contains(hand, c) {
+			hand = append(hand, c)
+		}
+	}
+	switch g.Phase {
+	case WorkPhase:
+		for _, c := range hand {
+			if g.Stats.Works > 0 && slices.Contains(c.CardTypes, WorkType) {
+				moves = append(moves, Action{Kind: ActionPlayWork, Card: c})
+			}
+		}
+	case BlessingPhase:
+		// faith cards can be played all at once or one at a time
+		_, faith := where(hand, func(c *Card) bool { return slices.Contains(c.CardTypes, FaithType) })
+		if len(faith) > 0 {
+			moves = append(moves, Action{Kind: ActionPlayFaith})
+		}
+		for _, c := range faith {
+			moves = append(moves, Action{Kind: ActionPlayFaith, Card: c})
+		}
+		for _, p := range g.Kingdom.Piles {
+			if g.canBuy(p) {
+				moves = append(moves, Action{Kind: ActionBuy, Card: p.Top()})
+			}
+		}
+	}
+	return append(moves, Action{Kind: ActionEndPhase})
+}
+
+// makes the move if it is legal, returning false if it isn't
+func (g *Game) MakeMove(a Action) bool {
+	if !slices.Contains(g.LegalMoves(), a) {
+		return false
+	}
+	switch a.Kind {
+	case ActionPlayWork:
+		g.playWork(a.Card)
+	case ActionPlayFaith:
+		if a.Card != nil {
+			g.playFaith(a.Card)
+		} else {
+			_, faith := where(g.MyCards.Hand, func(c *Card) bool { return slices.Contains(c.CardTypes, FaithType) })
+			g.playFaith(faith...)
+		}
+	case ActionBuy:
+		g.buy(g.Kingdom.PileWithTop(a.Card.Name))
+	case ActionEndPhase:
+		if g.Phase == WorkPhase {
+			g.startBlessing()
+		} else {
+			g.rest()
+		}
+	case ActionChoose:
+		g.decide(a.Card)
+	case ActionSkip:
+		g.skipDecision()
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLegalMovesInWorkPhase(t *testing.T) {
+	w := newTestWorld(t)
+	g := w.games[0]
+	setCards(g, []*Card{Craft, Study, Festival, Craft}, nil, nil)
+	want := []Action{{Kind: ActionPlayWork, Card: Craft}, {Kind: ActionPlayWork, Card: Festival}, {Kind: ActionEndPhase}}
+	if got := g.LegalMoves(); !slices.Equal(got, want) {
+		t.Errorf("moves are %v, want %v", got, want)
+	}
+	// with no works left, only the phase can end
+	g.Stats.Works = 0
+	if got := g.LegalMoves(); !slices.Equal(got, []Action{{Kind: ActionEndPhase}}) {
+		t.Errorf("with no works the moves are %v", got)
+	}
+	// nothing is legal on someone else's turn
+	if got := w.games[1].LegalMoves(); got != nil {
+		t.Errorf("p2 can make %v on p1's turn", got)
+	}
+}
+
+func TestLegalMovesInBlessingPhase(t *testing.T) {
+	w := newTestWorld(t)
+	g := w.games[0]
+	setCards(g, []*Card{Study, Craft, Prayer, Study}, nil, nil)
+	g.Phase, g.Stats = BlessingPhase, TurnStats{Blessings: 1, Faith: 3}
+	moves := g.LegalMoves()
+	for _, a := range []Action{{Kind: ActionPlayFaith}, {Kind: ActionPlayFaith, Card: Study}, {Kind: ActionPlayFaith, Card: Prayer}, {Kind: ActionBuy, Card: Prayer}, {Kind: ActionEndPhase}} {
+		if !slices.Contains(moves, a) {
+			t.Errorf("%v is missing from %v", a, moves)
+		}
+	}
+	for _, a := range moves {
+		if a.Kind == ActionBuy && a.Card.Cost > 3 || a.Kind == ActionPlayWork {
+			t.Errorf("%v shouldn't be legal", a)
+		}
+	}
+	for i, a := range moves {
+		if slices.Index(moves, a) != i {
+			t.Errorf("%v is in %v twice", a, moves)
+		}
+	}
+	// without a blessing nothing can be bought
+	g.Stats.Blessings = 0
+	if slices.ContainsFunc(g.LegalMoves(), func(a Action) bool { return a.Kind == ActionBuy }) {
+		t.Error("bought without a blessing")
+	}
+}
+
+func TestLegalMovesForDecisions(t *testing.T) {
+	w := newTestWorld(t)
+	g := w.games[0]
+	setCards(g, []*Card{Duplication, Festival, Craft}, []*Card{Study}, nil)
+	g.MakeMove(Action{Kind: ActionPlayWork, Card: Duplication})
+	moves := g.LegalMoves()
+	for _, a := range []Action{{Kind: ActionChoose, Card: Festival}, {Kind: ActionChoose, Card: Craft}} {
+		if !slices.Contains(moves, a) {
+			t.Errorf("%v is missing from %v", a, moves)
+		}
+	}
+	if _, skippable := g.PromptDecision(); skippable != slices.Contains(moves, Action{Kind: ActionSkip}) {
+		t.Errorf("skippable is %v but the moves are %v", skippable, moves)
+	}
+	if slices.Contains(moves, Action{Kind: ActionEndPhase}) {
+		t.Error("the phase can't end while deciding")
+	}
+	// with nothing to choose the decision can only be skipped
+	if got := decisionMoves(nil, false); !slices.Equal(got, []Action{{Kind: ActionSkip}}) {
+		t.Errorf("with no choices the moves are %v", got)
+	}
+}
+
+func TestMakeMoveRejectsIllegalMoves(t *testing.T) {
+	w := newTestWorld(t)
+	g := w.games[0]
+	setCards(g, []*Card{Craft, Study}, nil, nil)
+	for _, a := range []Action{
+		{Kind: ActionPlayWork, Card: Festival},
+		{Kind: ActionPlayWork, Card: Study},
+		{Kind: ActionPlayFaith},
+		{Kind: ActionBuy, Card: Prayer},
+		{Kind: ActionChoose, Card: Craft},
+	} {
+		if g.MakeMove(a) {
+			t.Errorf("made %v", a)
+		}
+	}
+	if !slices.Equal(g.MyCards.Hand, []*Card{Craft, Study}) || g.Phase != WorkPhase {
+		t.Errorf("illegal moves changed the game: hand %v, phase %s", CardNames(g.MyCards.Hand), g.Phase)
+	}
+	if w.games[1].MakeMove(Action{Kind: ActionEndPhase}) {
+		t.Error("p2 ended p1's phase")
+	}
+	if !g.MakeMove(Action{Kind: ActionEndPhase}) {
+		t.Error("couldn't end the work phase")
+	}
+	if w.sync(); g.Phase != BlessingPhase {
+		t.Errorf("ending the work phase moved on to %s", g.Phase)
+	}
+}
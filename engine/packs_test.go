@@ -0,0 +1,109 @@
+package engine
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/zehongharryqu/kingdom-of-heaven/assets"
+)
+
+// a pack with the cards, given as the JSON in its manifest
+func testPack(t *testing.T, cards string) *assets.Pack {
+	t.Helper()
+	p, err := assets.LoadPack(fstest.MapFS{
+		assets.PackManifest: {Data: []byte(`{"name": "psalms", "version": "1.0", "cards": ` + cards + `}`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// puts the card registry back the way it was when the test ends
+func restoreRegistry(t *testing.T) {
+	all, nonBase, names, sets, packs := AllCards, NonBaseCards, maps.Clone(CardNameMap), CardSets, Packs
+	t.Cleanup(func() {
+		AllCards, NonBaseCards, CardNameMap, CardSets, Packs = all, nonBase, names, sets, packs
+	})
+}
+
+func TestRegisterPack(t *testing.T) {
+	restoreRegistry(t)
+	p := testPack(t, `[
+		{"name": "Harp", "cost": 3, "types": ["Work"], "text": "+2 Faith", "effect": {"faith": 2}},
+		{"name": "Lyre", "cost": 4, "types": ["Work"], "set": "base", "text": "+1 Card", "effect": {"cards": 1}}
+	]`)
+	if err := registerPack(p); err != nil {
+		t.Fatal(err)
+	}
+	harp, lyre := CardNameMap["Harp"], CardNameMap["Lyre"]
+	if harp == nil || lyre == nil || harp.faith != 2 || !slices.Contains(NonBaseCards, harp) || !slices.Contains(AllCards, lyre) {
+		t.Fatal("the pack's cards aren't registered")
+	}
+	// cards without a set go in one named after the pack
+	if harp.set != "psalms" || lyre.set != "base" || !slices.Contains(CardSets, "psalms") {
+		t.Errorf("sets are %s and %s, sets %v", harp.set, lyre.set, CardSets)
+	}
+	if sums := PackChecksums(); len(sums) != 1 || !strings.HasPrefix(sums[0], "psalms@1.0:") {
+		t.Errorf("checksums %v", sums)
+	}
+}
+
+func TestRegisterPackErrors(t *testing.T) {
+	restoreRegistry(t)
+	for _, tc := range []struct {
+		cards, want string
+	}{
+		{`[{"name": "Craft", "types": ["Work"]}]`, "pack psalms: duplicate card Craft"},
+		{`[{"name": "Harp", "types": ["Work"]}, {"name": "Harp", "types": ["Work"]}]`, "pack psalms: duplicate card Harp"},
+		{`[{"name": "Harp", "types": ["Song"]}]`, "pack psalms: card Harp has unknown type Song"},
+		{`[{"name": "Harp", "types": ["Work"], "bigArt": "harp.png"}]`, "pack psalms: card Harp is missing art: open harp.png: file does not exist"},
+	} {
+		if err := registerPack(testPack(t, tc.cards)); err == nil || err.Error() != tc.want {
+			t.Errorf("%s: %v, want %q", tc.cards, err, tc.want)
+		}
+	}
+	// nothing from a bad pack is registered
+	if _, ok := CardNameMap["Harp"]; ok || len(Packs) != 0 {
+		t.Error("a pack that failed left cards behind")
+	}
+}
+
+// players with different versions of a pack can tell
+func TestPackChecksums(t *testing.T) {
+	a, b := testPack(t, `[]`), testPack(t, `[{"name": "Harp", "types": ["Work"]}]`)
+	if a.Checksum == b.Checksum {
+		t.Error("different packs have the same checksum")
+	}
+	if c := testPack(t, `[]`); c.Checksum != a.Checksum {
+		t.Error("the same pack has different checksums")
+	}
+	pd := &PlayerData{}
+	if pd.SamePacks(nil) {
+		t.Error("a player who hasn't said what packs they have matches")
+	}
+	pd.packs = []string{"psalms@1.0:" + a.Checksum}
+	if !pd.SamePacks([]string{"psalms@1.0:" + a.Checksum}) || pd.SamePacks([]string{"psalms@1.0:" + b.Checksum}) {
+		t.Error("packs compared wrongly")
+	}
+}
+
+// nobody starts picking the kingdom until everyone has the same packs
+func TestDifferentPacksDontStart(t *testing.T) {
+	w := newLobby()
+	g := w.games[0]
+	same := g.Players["p2"].packs
+	g.Players["p2"].packs = []string{"psalms@1.0:0123456789abcdef"}
+	g.StartIfReady()
+	if g.State != Lobby {
+		t.Errorf("started with different packs")
+	}
+	g.Players["p2"].packs = same
+	g.StartIfReady()
+	if g.State != Picking {
+		t.Errorf("didn't start with the same packs")
+	}
+}
@@ -0,0 +1,67 @@
+// the screen where the host picks the kingdom before the game starts
+package engine
+
+import "strings"
+
+// the kingdom being picked, which every client shows
+type KingdomPicks struct {
+	Picked, Banned []*Card
+	// name of the preset last loaded, if any
+	Preset string
+}
+
+// turns the picks into message fields of the form key=value
+func (kp *KingdomPicks) Encode() []string {
+	return []string{
+		"picked=" + strings.Join(CardNames(kp.Picked), ","),
+		"banned=" + strings.Join(CardNames(kp.Banned), ","),
+		"preset=" + kp.Preset,
+	}
+}
+
+// reads picks from message fields of the form key=value, ignoring keys it doesn't know
+func decodeKingdomPicks(fields []string) KingdomPicks {
+	var kp KingdomPicks
+	for _, field := range fields {
+		key, value, _ := strings.Cut(field, "=")
+		switch key {
+		case "picked":
+			kp.Picked = namedCards(value)
+		case "banned":
+			kp.Banned = namedCards(value)
+		case "preset":
+			kp.Preset = value
+		}
+	}
+	return kp
+}
+
+// returns the names of the cards
+func CardNames(cards []*Card) []string {
+	names := make([]string, len(cards))
+	for i, c := range cards {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// returns the cards from a comma separated list of names, skipping unknown names
+func namedCards(list string) []*Card {
+	var cards []*Card
+	for _, name := range strings.Split(list, ",") {
+		if c, ok := CardNameMap[name]; ok {
+			cards = append(cards, c)
+		}
+	}
+	return cards
+}
+
+// the cards shown on the picker, which is every card in the chosen sets
+func (g *Game) PickerCards() []*Card {
+	return kingdomPool(g.Options, nil)
+}
+
+// tells everyone the new picks
+func (g *Game) SendPicks(kp KingdomPicks) {
+	g.Conn.Send(append([]string{PickedKingdom}, kp.Encode()...))
+}
@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"math/rand"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// two players in a local room who have both readied up
+func newLobby() *World {
+	room := NewLocalRoom()
+	w := &World{}
+	for i, name := range []string{"p1", "p2"} {
+		g := NewGame()
+		g.State = Lobby
+		g.Name = name
+		g.RNG = rand.New(rand.NewSource(int64(i)))
+		conn := room.Join(name, i)
+		g.Conn = conn
+		conn.Send(append([]string{HasPacks, name}, PackChecksums()...))
+		conn.Send([]string{ToggledReady, name})
+		w.games = append(w.games, g)
+		w.conns = append(w.conns, conn)
+	}
+	w.sync()
+	return w
+}
+
+func TestEveryoneSeesThePicks(t *testing.T) {
+	w := newLobby()
+	for _, g := range w.games {
+		g.StartIfReady()
+	}
+	w.sync()
+	host, other := w.games[0], w.games[1]
+	if host.State != Picking || len(host.Picks.Picked) != KingdomSize || !reflect.DeepEqual(host.Picks, other.Picks) {
+		t.Fatalf("host picked %v, p2 sees %v", CardNames(host.Picks.Picked), CardNames(other.Picks.Picked))
+	}
+	preset := KingdomPresets[1]
+	host.SendPicks(KingdomPicks{Picked: preset.Kingdom, Banned: []*Card{Festival}, Preset: preset.Name})
+	w.sync()
+	if !slices.Equal(other.Picks.Picked, preset.Kingdom) || !slices.Equal(other.Picks.Banned, []*Card{Festival}) || other.Picks.Preset != preset.Name {
+		t.Errorf("p2 sees %+v after the host loaded %s", other.Picks, preset.Name)
+	}
+}
+
+func TestKingdomPicksRoundTrip(t *testing.T) {
+	kp := KingdomPicks{Picked: []*Card{Craft, Festival}, Banned: []*Card{Desires}, Preset: "First Game"}
+	if got := decodeKingdomPicks(kp.Encode()); !reflect.DeepEqual(got, kp) {
+		t.Errorf("decoded %+v, want %+v", got, kp)
+	}
+	// cards this client doesn't have are left out
+	if got := decodeKingdomPicks([]string{"picked=Craft,Unknown"}); !slices.Equal(got.Picked, []*Card{Craft}) {
+		t.Errorf("decoded %v", CardNames(got.Picked))
+	}
+}
+
+func TestBannedCardsAreNeverPicked(t *testing.T) {
+	banned := []*Card{Craft, Festival, Shield}
+	for seed := range int64(50) {
+		if kingdom := PickKingdom(DefaultRoomOptions(), banned, rand.New(rand.NewSource(seed))); slices.ContainsFunc(kingdom, func(c *Card) bool { return slices.Contains(banned, c) }) {
+			t.Errorf("seed %d picked a banned card: %v", seed, CardNames(kingdom))
+		}
+	}
+}
+
+// presets are whole kingdoms of different verses
+func TestPresets(t *testing.T) {
+	for _, p := range KingdomPresets {
+		if len(p.Kingdom) != KingdomSize {
+			t.Errorf("%s has %d cards", p.Name, len(p.Kingdom))
+		}
+		for _, c := range p.Kingdom {
+			if !slices.Contains(NonBaseCards, c) || count(p.Kingdom, c) != 1 {
+				t.Errorf("%s has %s %d times", p.Name, c.Name, count(p.Kingdom, c))
+			}
+		}
+	}
+}
@@ -0,0 +1,360 @@
+package engine
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+	"strconv"
+)
+
+type PlayerCards struct {
+	Hand, Deck, Discard, decision []*Card
+	// cards set aside while resolving an effect
+	aside []*Card
+	// how many cards on top of the deck we know the order of, from putting them there since they were
+	// last shuffled
+	known int
+	// shuffles the deck
+	rng *rand.Rand
+}
+
+func InitPlayerCards(rng *rand.Rand) *PlayerCards {
+	return &PlayerCards{Discard: []*Card{Study, Study, Study, Study, Study, Study, Study, Parable, Parable, Parable}, rng: rng}
+}
+
+// returns a copy that can be drawn from without changing these cards
+func (pc *PlayerCards) clone() *PlayerCards {
+	return &PlayerCards{
+		Hand:     slices.Clone(pc.Hand),
+		Deck:     slices.Clone(pc.Deck),
+		Discard:  slices.Clone(pc.Discard),
+		decision: slices.Clone(pc.decision),
+		aside:    slices.Clone(pc.aside),
+		known:    pc.known,
+		rng:      pc.rng,
+	}
+}
+
+// makes sure there are at least n cards in deck if possible, shuffling the discard under it if not
+func (pc *PlayerCards) fillDeck(n int) {
+	if len(pc.Deck) < n {
+		// not enough in just deck, shuffle discard and put it on the bottom of the deck
+		pc.rng.Shuffle(len(pc.Discard), func(i, j int) {
+			pc.Discard[i], pc.Discard[j] = pc.Discard[j], pc.Discard[i]
+		})
+		pc.Deck = append(pc.Discard, pc.Deck...)
+		pc.Discard = nil
+	}
+}
+
+// draws n cards into dest and returns the result
+func (pc *PlayerCards) drawNCards(n int, dest []*Card) []*Card {
+	// fmt.Printf("hand %d, discard %d, deck %d, drawing %d cards\n", len(pc.hand), len(pc.discard), len(pc.deck), n)
+	if len(pc.Deck)+len(pc.Discard) < n {
+		// not enough in deck and discard, draw everything
+		dest = slices.Concat(dest, pc.Deck, pc.Discard)
+		pc.Deck = nil
+		pc.Discard = nil
+		pc.known = 0
+	} else {
+		// enough cards in deck and discard, shuffle discard if necessary and draw from deck
+		pc.fillDeck(n)
+		// draw into dest
+		dest = append(dest, pc.Deck[len(pc.Deck)-n:]...)
+		pc.Deck = pc.Deck[:len(pc.Deck)-n]
+		pc.known = max(pc.known-n, 0)
+	}
+	sortCards(dest)
+	// fmt.Printf("hand %d, discard %d, deck %d\n", len(pc.hand), len(pc.discard), len(pc.deck))
+	return dest
+}
+
+// sorts cards by type, then by cost descending, then by name
+func sortCards(cards []*Card) {
+	slices.SortFunc(cards, func(a, b *Card) int {
+		return cmp.Or(
+			cmp.Compare(slices.Min(a.CardTypes), slices.Min(b.CardTypes)),
+			cmp.Compare(b.Cost, a.Cost),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+}
+
+// puts cards onto the deck in order, so the last one ends up on top
+func (pc *PlayerCards) putOnDeck(cards ...*Card) {
+	pc.Deck = append(pc.Deck, cards...)
+	pc.known += len(cards)
+}
+
+// shuffles the part of the deck we don't know the order of, leaving the known cards on top
+func (pc *PlayerCards) shuffleUnknown(rng *rand.Rand) {
+	unknown := pc.Deck[:len(pc.Deck)-pc.known]
+	rng.Shuffle(len(unknown), func(i, j int) {
+		unknown[i], unknown[j] = unknown[j], unknown[i]
+	})
+}
+
+// sets cards aside until the effect setting them aside is done
+func (pc *PlayerCards) setAside(cards ...*Card) {
+	pc.aside = append(pc.aside, cards...)
+}
+
+// discards all the set aside cards and returns them
+func (pc *PlayerCards) discardAside() []*Card {
+	aside := pc.aside
+	pc.Discard = append(pc.Discard, aside...)
+	pc.aside = nil
+	return aside
+}
+
+// returns true if there are any works cards in hand
+func (pc *PlayerCards) HasWorks() bool {
+	for _, c := range pc.Hand {
+		if slices.Contains(c.CardTypes, WorkType) {
+			return true
+		}
+	}
+	return false
+}
+
+// kinds of kingdom pile
+const (
+	// every card is the same
+	UniformPile = iota
+	// two different cards, one half on top of the other
+	SplitPile
+	// more than two different cards
+	MixedPile
+)
+
+// a pile of cards in the kingdom. only the top card can be gained
+type Pile struct {
+	// the card's name, or the pile name shared by the cards in a split or mixed pile
+	name string
+	kind int
+	// the cards left, top last
+	cards []*Card
+	// piles outside the supply can't be bought and don't end the game when empty
+	supply bool
+	// where the pile is drawn in the grid on the mat
+	Slot int
+}
+
+// returns the card on top, or nil if the pile is empty
+func (p *Pile) Top() *Card {
+	if len(p.cards) == 0 {
+		return nil
+	}
+	return p.cards[len(p.cards)-1]
+}
+
+// removes the top card and returns it, or nil if the pile is empty
+func (p *Pile) take() *Card {
+	c := p.Top()
+	if c != nil {
+		p.cards = p.cards[:len(p.cards)-1]
+	}
+	return c
+}
+
+// returns true if the top card costs up to cost and has cardType (-1 for any), and can be gained by effects
+func (p *Pile) canGain(cost, cardType int) bool {
+	c := p.Top()
+	return p.supply && c != nil && c.Cost <= cost && (cardType == -1 || slices.Contains(c.CardTypes, cardType))
+}
+
+// what to show when the pile is hovered
+func (p *Pile) Label() string {
+	if p.kind == UniformPile {
+		return strconv.Itoa(len(p.cards))
+	}
+	return p.name + ": " + strconv.Itoa(len(p.cards))
+}
+
+// how many piles fit in a row of the grid on the mat
+const PileGridCols = 5
+
+type Kingdom struct {
+	Piles    []*Pile
+	Released []*Card
+	// decides when the game ends
+	rules *Ruleset
+	// where the released pile is drawn in the grid on the mat
+	ReleasedSlot int
+}
+
+// checks if the game is done
+func (k *Kingdom) GameDone() bool {
+	if p := k.pile(k.rules.endPile); p != nil && len(p.cards) == 0 {
+		return true
+	}
+	return k.emptyPiles() >= k.rules.emptyPiles
+}
+
+// returns a copy whose piles can be taken from without changing these
+func (k *Kingdom) clone() *Kingdom {
+	c := *k
+	c.Piles = make([]*Pile, len(k.Piles))
+	for i, p := range k.Piles {
+		pc := *p
+		pc.cards = slices.Clone(p.cards)
+		c.Piles[i] = &pc
+	}
+	c.Released = slices.Clone(k.Released)
+	return &c
+}
+
+// how many supply piles have run out
+func (k *Kingdom) emptyPiles() int {
+	n := 0
+	for _, p := range k.Piles {
+		if p.supply && len(p.cards) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// returns true if any pile has a card that can be gained costing up to cost and having cardType (-1 for any)
+func (k *Kingdom) canGain(cost, cardType int) bool {
+	return slices.ContainsFunc(k.Piles, func(p *Pile) bool { return p.canGain(cost, cardType) })
+}
+
+// returns the pile with the given name, or nil if there is none
+func (k *Kingdom) pile(name string) *Pile {
+	for _, p := range k.Piles {
+		if p.name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// returns the pile with the given card on top, or nil if it can't be gained from any pile
+func (k *Kingdom) PileWithTop(name string) *Pile {
+	for _, p := range k.Piles {
+		if c := p.Top(); c != nil && c.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// removes a card from the kingdom (e.g. when gained)
+func (k *Kingdom) RemoveCard(name string) {
+	if p := k.PileWithTop(name); p != nil {
+		p.take()
+	}
+}
+
+// places the piles in a grid on the mat: basic piles, the released pile and piles outside the supply
+// first, then the kingdom piles from the start of the next row
+func (k *Kingdom) layout(basic, kingdom, nonSupply []*Pile) {
+	slot := 0
+	place := func() int {
+		slot++
+		return slot - 1
+	}
+	for _, p := range basic {
+		p.Slot = place()
+	}
+	k.ReleasedSlot = place()
+	for _, p := range nonSupply {
+		p.Slot = place()
+	}
+	slot = (slot + PileGridCols - 1) / PileGridCols * PileGridCols
+	for _, p := range kingdom {
+		p.Slot = place()
+	}
+	k.Piles = slices.Concat(basic, kingdom, nonSupply)
+}
+
+// the name of the pile the card goes in
+func (c *Card) pileName() string {
+	if c.pile != "" {
+		return c.pile
+	}
+	return c.Name
+}
+
+// makes a pile of the given cards, cheapest on top, with size cards in total unless the cards say
+// how many of them there are
+func newPile(name string, cards []*Card, size int, supply bool) *Pile {
+	slices.SortFunc(cards, func(a, b *Card) int {
+		return cmp.Or(
+			cmp.Compare(b.Cost, a.Cost),
+			cmp.Compare(b.Name, a.Name),
+		)
+	})
+	p := &Pile{name: name, supply: supply}
+	switch len(cards) {
+	case 1:
+		p.kind = UniformPile
+	case 2:
+		p.kind = SplitPile
+	default:
+		p.kind = MixedPile
+	}
+	for i, c := range cards {
+		n := size / len(cards)
+		// the remainder goes on top
+		if i == len(cards)-1 {
+			n += size % len(cards)
+		}
+		if c.pileSize > 0 {
+			n = c.pileSize
+		}
+		for range n {
+			p.cards = append(p.cards, c)
+		}
+	}
+	return p
+}
+
+// create a new kingdom given the 10 verses, number of players and ruleset
+func InitKingdom(verses []*Card, n int, rules *Ruleset) *Kingdom {
+	sizes := rules.pileSizes(n)
+	var basic []*Pile
+	for _, c := range []*Card{Study, Prayer, Devotion, Temptation, Parable, Sermon, Miracle} {
+		basic = append(basic, newPile(c.Name, []*Card{c}, sizes.basic[c.Name], true))
+	}
+	// a verse brings the rest of its pile with it
+	var names []string
+	for _, c := range verses {
+		if !slices.Contains(names, c.pileName()) {
+			names = append(names, c.pileName())
+		}
+	}
+	var kingdom []*Pile
+	for _, name := range names {
+		_, cards := where(NonBaseCards, func(c *Card) bool { return c.pileName() == name })
+		size := sizes.kingdom
+		if slices.ContainsFunc(cards, func(c *Card) bool { return slices.Contains(c.CardTypes, GloryType) }) {
+			size = sizes.glory
+		}
+		kingdom = append(kingdom, newPile(name, cards, size, true))
+	}
+	// sort kingdom by the cost and name of the top card
+	slices.SortFunc(kingdom, func(a, b *Pile) int {
+		return cmp.Or(
+			cmp.Compare(a.Top().Cost, b.Top().Cost),
+			cmp.Compare(a.name, b.name),
+		)
+	})
+	// cards outside the supply that the verses' scripts gain get their own piles
+	var nonSupply []*Pile
+	for _, p := range kingdom {
+		for _, c := range slices.Compact(slices.Clone(p.cards)) {
+			for _, name := range c.script.cardsNamed() {
+				extra, ok := CardNameMap[name]
+				if ok && extra.nonSupply && !slices.ContainsFunc(nonSupply, func(p *Pile) bool { return p.name == extra.pileName() }) {
+					_, cards := where(AllCards, func(c *Card) bool { return c.nonSupply && c.pileName() == extra.pileName() })
+					nonSupply = append(nonSupply, newPile(extra.pileName(), cards, sizes.kingdom, false))
+				}
+			}
+		}
+	}
+	k := &Kingdom{rules: rules}
+	k.layout(basic, kingdom, nonSupply)
+	return k
+}
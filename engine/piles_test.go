@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNewPile(t *testing.T) {
+	cheap, middle, dear := &Card{Name: "Cheap", Cost: 2}, &Card{Name: "Middle", Cost: 3, pileSize: 1}, &Card{Name: "Dear", Cost: 5}
+	for _, tc := range []struct {
+		name  string
+		cards []*Card
+		size  int
+		kind  int
+		// bottom first
+		want  []*Card
+		label string
+	}{
+		{"Dear", []*Card{dear}, 3, UniformPile, []*Card{dear, dear, dear}, "3"},
+		// the remainder goes to the cheapest, on top
+		{"Pair", []*Card{cheap, dear}, 5, SplitPile, []*Card{dear, dear, cheap, cheap, cheap}, "Pair: 5"},
+		// a card can say how many of it there are
+		{"Trio", []*Card{cheap, middle, dear}, 6, MixedPile, []*Card{dear, dear, middle, cheap, cheap}, "Trio: 5"},
+	} {
+		p := newPile(tc.name, tc.cards, tc.size, true)
+		if p.kind != tc.kind || !slices.Equal(p.cards, tc.want) || p.Label() != tc.label {
+			t.Errorf("%s: kind %d, cards %v, label %q", tc.name, p.kind, CardNames(p.cards), p.Label())
+		}
+	}
+}
+
+// once the top half of a split pile is gone, the card under it can be gained
+func TestSplitPileRevealsNextCard(t *testing.T) {
+	cheap, dear := &Card{Name: "Cheap", Cost: 2}, &Card{Name: "Dear", Cost: 5}
+	k := &Kingdom{Piles: []*Pile{newPile("Pair", []*Card{cheap, dear}, 4, true)}, rules: Rulesets[0]}
+	if k.PileWithTop("Dear") != nil || k.pile("Pair") == nil {
+		t.Fatal("the card under the top half can be gained")
+	}
+	k.RemoveCard("Cheap")
+	k.RemoveCard("Cheap")
+	if p := k.PileWithTop("Dear"); p == nil || len(p.cards) != 2 {
+		t.Errorf("Dear isn't on top after the Cheaps are gone")
+	}
+}
+
+// piles outside the supply can't be bought or gained by supply effects, and don't end the game
+func TestNonSupplyPile(t *testing.T) {
+	spirit := &Card{Name: "Spirit", CardTypes: []int{WorkType}}
+	k := &Kingdom{Piles: []*Pile{newPile("Spirit", []*Card{spirit}, 1, false)}, rules: &Ruleset{emptyPiles: 1, endPile: "Miracle"}}
+	g := &Game{Stats: TurnStats{Blessings: 1, Faith: 5}}
+	if g.canBuy(k.Piles[0]) || k.canGain(5, -1) {
+		t.Error("a non-supply pile can be bought or gained")
+	}
+	k.RemoveCard("Spirit")
+	if k.emptyPiles() != 0 || k.GameDone() {
+		t.Error("an empty non-supply pile counts towards the end")
+	}
+}
+
+func TestInitKingdomPiles(t *testing.T) {
+	restoreRegistry(t)
+	cheap := &Card{Name: "Cheap", Cost: 2, CardTypes: []int{WorkType}, pile: "Pair"}
+	dear := &Card{Name: "Dear", Cost: 5, CardTypes: []int{WorkType}, pile: "Pair"}
+	spirit := &Card{Name: "Spirit", CardTypes: []int{WorkType}, nonSupply: true}
+	giver := scriptCard(t, "gain Spirit")
+	NonBaseCards = slices.Concat(NonBaseCards, []*Card{cheap, dear, giver})
+	AllCards = slices.Concat(AllCards, []*Card{cheap, dear, spirit, giver})
+	CardNameMap["Spirit"] = spirit
+	// one card of a split pile brings the other with it
+	k := InitKingdom([]*Card{dear, giver}, 2, Rulesets[0])
+	var names []string
+	for _, p := range k.Piles {
+		names = append(names, p.name)
+	}
+	if want := []string{"Study", "Prayer", "Devotion", "Temptation", "Parable", "Sermon", "Miracle", "Test", "Pair", "Spirit"}; !slices.Equal(names, want) {
+		t.Fatalf("piles %v, want %v", names, want)
+	}
+	pair, spirits := k.pile("Pair"), k.pile("Spirit")
+	if len(pair.cards) != 10 || pair.Top() != cheap || pair.kind != SplitPile || spirits.supply {
+		t.Errorf("Pair has %d cards with %s on top, Spirit supply %v", len(pair.cards), pair.Top().Name, spirits.supply)
+	}
+	// basic piles, the released pile and piles outside the supply, then the kingdom from a new row
+	if k.pile("Miracle").Slot != 6 || k.ReleasedSlot != 7 || spirits.Slot != 8 || k.pile("Test").Slot != PileGridCols*2 || pair.Slot != PileGridCols*2+1 {
+		t.Errorf("slots Miracle %d, released %d, Spirit %d, Test %d, Pair %d", k.pile("Miracle").Slot, k.ReleasedSlot, spirits.Slot, k.pile("Test").Slot, pair.Slot)
+	}
+}
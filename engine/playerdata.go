@@ -0,0 +1,49 @@
+package engine
+
+import "slices"
+
+// the longest name a player can have
+const MaxNameChars = 10
+
+type PlayerData struct {
+	Name  string
+	pid   int
+	Ready bool
+	glory int
+	// whether the player has sent their final glory
+	scored bool
+	// how many turns the player has started
+	turns int
+	// the card packs the player has loaded, as name@version:checksum, or nil if we don't know yet
+	packs []string
+	// the cards the player owns, as far as everyone can tell from what they gained and released
+	cards []*Card
+	// the cards everyone has seen go to the player's discard since they last shuffled it: what they
+	// had in play, gained and discarded. the hand they discard at the end of a turn isn't seen
+	discard []*Card
+	// how the host set up the player if they are a bot, or nil for people
+	Bot *BotSettings
+}
+
+// whether the player has told us they have exactly the given packs
+func (pd *PlayerData) SamePacks(packs []string) bool {
+	return pd.packs != nil && slices.Equal(pd.packs, packs)
+}
+
+func (pd *PlayerData) setGlory(glory int) {
+	pd.glory = glory
+	pd.scored = true
+}
+
+func (pd *PlayerData) toggleReady() {
+	pd.Ready = !pd.Ready
+}
+
+// removes one copy of each card from the ones the player owns
+func (pd *PlayerData) release(cards ...*Card) {
+	for _, c := range cards {
+		if i := slices.Index(pd.cards, c); i != -1 {
+			pd.cards = slices.Delete(pd.cards, i, i+1)
+		}
+	}
+}
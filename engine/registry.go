@@ -0,0 +1,208 @@
+// loads the cards from the card data file
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"log"
+	"slices"
+
+	"github.com/zehongharryqu/kingdom-of-heaven/assets"
+)
+
+// a card as written in the card data file
+type CardData struct {
+	Name  string   `json:"name"`
+	Cost  int      `json:"cost"`
+	Glory int      `json:"glory"`
+	Types []string `json:"types"`
+	// what roles the card plays in a kingdom, e.g. village or draw
+	Tags []string `json:"tags"`
+	// which set the card comes from
+	Set string `json:"set"`
+	// basic cards are in every kingdom
+	Basic bool `json:"basic"`
+	// cards with the same pile name share one kingdom pile, split or mixed
+	Pile string `json:"pile"`
+	// how many of the card there are, if not the usual number
+	PileSize int `json:"pileSize"`
+	// cards outside the supply are never in the kingdom, but get a pile when a verse's script gains them
+	NonSupply bool `json:"nonSupply"`
+	// art file names, which can be left out to render the card from its rules text
+	BigArt   string `json:"bigArt"`
+	SmallArt string `json:"smallArt"`
+	// what playing the card gives the turn, on top of anything its rules text does
+	Effect struct {
+		Cards     int `json:"cards"`
+		Works     int `json:"works"`
+		Blessings int `json:"blessings"`
+		Faith     int `json:"faith"`
+	} `json:"effect"`
+	Text string `json:"text"`
+	// what the card does beyond its effect, in the card script language, one command per line
+	Script []string `json:"script"`
+}
+
+// all cards, in the order of the card data file
+var AllCards = loadCards(assets.CardData)
+
+// cards for randomization
+var NonBaseCards = nonBaseCards()
+
+// convert string name into card
+var CardNameMap = cardNameMap()
+
+// reads the card data file, stopping the game if any card is invalid
+func loadCards(data []byte) []*Card {
+	var cds []CardData
+	if err := json.Unmarshal(data, &cds); err != nil {
+		log.Fatal("reading card data: ", err)
+	}
+	var cards []*Card
+	for _, cd := range cds {
+		if slices.ContainsFunc(cards, func(c *Card) bool { return c.Name == cd.Name }) {
+			log.Fatal("duplicate card " + cd.Name)
+		}
+		c, err := newCard(cd, assets.LoadArt)
+		if err != nil {
+			log.Fatal(err)
+		}
+		cards = append(cards, c)
+	}
+	return cards
+}
+
+// the card packs that were loaded, in the order they were registered
+var Packs []*assets.Pack
+
+// adds a pack's cards to the registry next to the built in ones. cards in the pack without a set
+// are put in a set named after the pack
+func registerPack(p *assets.Pack) error {
+	var cds []CardData
+	if err := json.Unmarshal(p.Cards, &cds); err != nil {
+		return fmt.Errorf("pack %s: %w", p.Name, err)
+	}
+	var cards []*Card
+	for _, cd := range cds {
+		if _, ok := CardNameMap[cd.Name]; ok || slices.ContainsFunc(cards, func(c *Card) bool { return c.Name == cd.Name }) {
+			return fmt.Errorf("pack %s: duplicate card %s", p.Name, cd.Name)
+		}
+		if cd.Set == "" {
+			cd.Set = p.Name
+		}
+		c, err := newCard(cd, p.LoadArt)
+		if err != nil {
+			return fmt.Errorf("pack %s: %w", p.Name, err)
+		}
+		cards = append(cards, c)
+	}
+	for _, c := range cards {
+		AllCards = append(AllCards, c)
+		CardNameMap[c.Name] = c
+		if !c.basic && !c.nonSupply {
+			NonBaseCards = append(NonBaseCards, c)
+			if !slices.Contains(CardSets, c.set) {
+				CardSets = append(CardSets, c.set)
+			}
+		}
+	}
+	Packs = append(Packs, p)
+	return nil
+}
+
+// describes the loaded packs as name@version:checksum, sorted, for players to compare
+func PackChecksums() []string {
+	var sums []string
+	for _, p := range Packs {
+		sums = append(sums, p.Name+"@"+p.Version+":"+p.Checksum)
+	}
+	slices.Sort(sums)
+	return sums
+}
+
+// creates a card from its data, checking its types and loading its art with loadArt
+func newCard(cd CardData, loadArt func(name string) (image.Image, error)) (*Card, error) {
+	if cd.Name == "" {
+		return nil, errors.New("card with no name")
+	}
+	c := &Card{
+		Name:      cd.Name,
+		Cost:      cd.Cost,
+		glory:     cd.Glory,
+		faith:     cd.Effect.Faith,
+		cards:     cd.Effect.Cards,
+		works:     cd.Effect.Works,
+		blessings: cd.Effect.Blessings,
+		set:       cd.Set,
+		basic:     cd.Basic,
+		pile:      cd.Pile,
+		pileSize:  cd.PileSize,
+		nonSupply: cd.NonSupply,
+		tags:      cd.Tags,
+		Text:      cd.Text,
+	}
+	if len(cd.Script) > 0 {
+		var err error
+		if c.script, err = parseScript(cd.Script); err != nil {
+			return nil, fmt.Errorf("card %s script: %w", cd.Name, err)
+		}
+	}
+	for _, name := range cd.Types {
+		t := cardType(name)
+		if t == -1 {
+			return nil, fmt.Errorf("card %s has unknown type %s", cd.Name, name)
+		}
+		c.CardTypes = append(c.CardTypes, t)
+	}
+	if len(c.CardTypes) == 0 {
+		return nil, fmt.Errorf("card %s has no types", cd.Name)
+	}
+	// cards without art get a frame rendered from their rules text
+	var err error
+	if cd.BigArt != "" {
+		if c.ArtBig, err = loadArt(cd.BigArt); err != nil {
+			return nil, fmt.Errorf("card %s is missing art: %w", cd.Name, err)
+		}
+	}
+	if cd.SmallArt != "" {
+		if c.ArtSmall, err = loadArt(cd.SmallArt); err != nil {
+			return nil, fmt.Errorf("card %s is missing art: %w", cd.Name, err)
+		}
+	}
+	return c, nil
+}
+
+// returns the card type with the given name, or -1 if there is none
+func cardType(name string) int {
+	for t, n := range CardTypeNames {
+		if n == name {
+			return t
+		}
+	}
+	return -1
+}
+
+// returns the cards that can be picked for the kingdom
+func nonBaseCards() []*Card {
+	_, cards := where(AllCards, func(c *Card) bool { return !c.basic && !c.nonSupply })
+	return cards
+}
+
+func cardNameMap() map[string]*Card {
+	m := make(map[string]*Card, len(AllCards))
+	for _, c := range AllCards {
+		m[c.Name] = c
+	}
+	return m
+}
+
+// returns the card with the given name, stopping the game if there is none
+func mustCard(name string) *Card {
+	c, ok := CardNameMap[name]
+	if !ok {
+		log.Fatal("card data is missing " + name)
+	}
+	return c
+}
@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"errors"
+	"image"
+	"slices"
+	"testing"
+)
+
+// loads art.png as a blank image and fails for anything else
+func testArt(name string) (image.Image, error) {
+	if name != "art.png" {
+		return nil, errors.New("no file " + name)
+	}
+	return image.NewRGBA(image.Rect(0, 0, 1, 1)), nil
+}
+
+func TestNewCard(t *testing.T) {
+	cd := CardData{Name: "Test", Cost: 3, Glory: 1, Types: []string{"Work", "Glory"}, BigArt: "art.png", Text: "+1 Card", Script: []string{"draw 1"}}
+	cd.Effect.Works = 2
+	c, err := newCard(cd, testArt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Cost != 3 || c.glory != 1 || c.works != 2 || len(c.CardTypes) != 2 || c.ArtBig == nil || c.ArtSmall != nil || c.script == nil {
+		t.Errorf("card %+v doesn't match its data", c)
+	}
+}
+
+func TestNewCardErrors(t *testing.T) {
+	for _, tc := range []struct {
+		cd   CardData
+		want string
+	}{
+		{CardData{Types: []string{"Work"}}, "card with no name"},
+		{CardData{Name: "Test"}, "card Test has no types"},
+		{CardData{Name: "Test", Types: []string{"Work", "Spell"}}, "card Test has unknown type Spell"},
+		{CardData{Name: "Test", Types: []string{"Work"}, SmallArt: "gone.png"}, "card Test is missing art: no file gone.png"},
+		{CardData{Name: "Test", Types: []string{"Work"}, Script: []string{"fly 2"}}, "card Test script: line 1: unknown command fly"},
+	} {
+		if _, err := newCard(tc.cd, testArt); err == nil || err.Error() != tc.want {
+			t.Errorf("newCard(%+v) = %v, want %q", tc.cd, err, tc.want)
+		}
+	}
+}
+
+// every card in the data file loads once, with its art, and only the verses are picked for kingdoms
+func TestCardData(t *testing.T) {
+	if len(CardNameMap) != len(AllCards) {
+		t.Errorf("%d names for %d cards", len(CardNameMap), len(AllCards))
+	}
+	for _, c := range AllCards {
+		if CardNameMap[c.Name] != c {
+			t.Errorf("%s isn't registered under its name", c.Name)
+		}
+		if c.ArtBig == nil || c.ArtSmall == nil {
+			t.Errorf("%s has no art", c.Name)
+		}
+		if verse := !c.basic && !c.nonSupply; verse != slices.Contains(NonBaseCards, c) {
+			t.Errorf("%s is a verse: %v, but NonBaseCards disagrees", c.Name, verse)
+		}
+	}
+	for _, c := range []*Card{Study, Prayer, Devotion, Parable, Sermon, Miracle, Temptation} {
+		if !c.basic {
+			t.Errorf("%s should be basic", c.Name)
+		}
+	}
+}
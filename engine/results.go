@@ -0,0 +1,124 @@
+// works out who won and records the result
+package engine
+
+import (
+	"cmp"
+	"encoding/json"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the outcome of a game, written one per line to the results file for stats and ratings
+type GameResult struct {
+	Room    string         `json:"room"`
+	Rules   string         `json:"rules"`
+	Ranked  bool           `json:"ranked"`
+	Seed    int64          `json:"seed"`
+	Kingdom []string       `json:"kingdom"`
+	Players []PlayerResult `json:"players"`
+	Ended   time.Time      `json:"ended"`
+}
+
+// how one player did, with players who tie after every tie-breaker sharing a place
+type PlayerResult struct {
+	Name  string `json:"name"`
+	Glory int    `json:"glory"`
+	Turns int    `json:"turns"`
+	// 1 for the winners
+	Place  int  `json:"place"`
+	Shared bool `json:"shared"`
+}
+
+// compares two players by glory then the tie-breakers, better players first
+func comparePlayers(a, b PlayerResult, tieBreakers []int) int {
+	if c := cmp.Compare(b.Glory, a.Glory); c != 0 {
+		return c
+	}
+	for _, t := range tieBreakers {
+		switch t {
+		case TieFewerTurns:
+			if c := cmp.Compare(a.Turns, b.Turns); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// sorts the players from first to last and gives them places
+func rankPlayers(players []PlayerResult, tieBreakers []int) {
+	slices.SortFunc(players, func(a, b PlayerResult) int {
+		return cmp.Or(comparePlayers(a, b, tieBreakers), cmp.Compare(a.Name, b.Name))
+	})
+	for i := range players {
+		players[i].Place = i + 1
+		if i > 0 && comparePlayers(players[i-1], players[i], tieBreakers) == 0 {
+			players[i].Place = players[i-1].Place
+			players[i].Shared, players[i-1].Shared = true, true
+		}
+	}
+}
+
+// works out the result once every player has sent their glory, or returns nil if some haven't
+func (g *Game) gameResult() *GameResult {
+	rules := RulesetNamed(g.Options.Rules)
+	r := &GameResult{Room: g.Room, Rules: rules.Name, Ranked: g.Options.Ranked, Seed: g.Seed, Ended: time.Now()}
+	for _, p := range g.Kingdom.Piles {
+		if p.supply && !slices.ContainsFunc(p.cards, func(c *Card) bool { return c.basic }) {
+			r.Kingdom = append(r.Kingdom, p.name)
+		}
+	}
+	for _, pd := range g.Players {
+		if !pd.scored {
+			return nil
+		}
+		r.Players = append(r.Players, PlayerResult{Name: pd.Name, Glory: pd.glory, Turns: pd.turns})
+	}
+	rankPlayers(r.Players, rules.tieBreakers)
+	return r
+}
+
+// adds the result to the end of the results file
+func (r *GameResult) record(path string) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// e.g. "alice wins!" or "alice and bob share the win!"
+func (r *GameResult) headline() string {
+	var winners []string
+	for _, p := range r.Players {
+		if p.Place == 1 {
+			winners = append(winners, p.Name)
+		}
+	}
+	if len(winners) == 1 {
+		return winners[0] + " wins!"
+	}
+	return strings.Join(winners[:len(winners)-1], ", ") + " and " + winners[len(winners)-1] + " share the win!"
+}
+
+// the final scores with places, ties marked with =
+func (r *GameResult) String() string {
+	msg := r.headline() + "\n\nFinal Scores:\n"
+	for _, p := range r.Players {
+		place := strconv.Itoa(p.Place)
+		if p.Shared {
+			place += "="
+		}
+		msg += place + strings.Repeat(" ", 4-len(place)) + p.Name + strings.Repeat(" ", MaxNameChars+1-len(p.Name)) + "| " + strconv.Itoa(p.Glory) + " Glory in " + strconv.Itoa(p.Turns) + " turns\n"
+	}
+	return msg + "\nSeed: " + strconv.FormatInt(r.Seed, 10) + "\n"
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRankPlayers(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		players []PlayerResult
+		// names in order, with their places and whether they are shared
+		want []PlayerResult
+	}{
+		{"glory", []PlayerResult{{Name: "a", Glory: 5}, {Name: "b", Glory: 9}}, []PlayerResult{{Name: "b", Glory: 9, Place: 1}, {Name: "a", Glory: 5, Place: 2}}},
+		{"fewer turns", []PlayerResult{{Name: "a", Glory: 9, Turns: 12}, {Name: "b", Glory: 9, Turns: 11}}, []PlayerResult{{Name: "b", Glory: 9, Turns: 11, Place: 1}, {Name: "a", Glory: 9, Turns: 12, Place: 2}}},
+		{"shared win", []PlayerResult{{Name: "c", Glory: 2, Turns: 5}, {Name: "b", Glory: 9, Turns: 5}, {Name: "a", Glory: 9, Turns: 5}}, []PlayerResult{{Name: "a", Glory: 9, Turns: 5, Place: 1, Shared: true}, {Name: "b", Glory: 9, Turns: 5, Place: 1, Shared: true}, {Name: "c", Glory: 2, Turns: 5, Place: 3}}},
+	} {
+		rankPlayers(tc.players, []int{TieFewerTurns})
+		if !slices.Equal(tc.players, tc.want) {
+			t.Errorf("%s: ranked %+v, want %+v", tc.name, tc.players, tc.want)
+		}
+	}
+	// without tie-breakers, fewer turns doesn't help
+	players := []PlayerResult{{Name: "a", Glory: 9, Turns: 12}, {Name: "b", Glory: 9, Turns: 11}}
+	if rankPlayers(players, nil); !players[0].Shared || players[1].Place != 1 {
+		t.Errorf("ranked %+v with no tie-breakers", players)
+	}
+}
+
+func TestResultString(t *testing.T) {
+	r := &GameResult{Seed: 4, Players: []PlayerResult{{"amy", 9, 5, 1, true}, {"bo", 9, 5, 1, true}, {"cy", 3, 5, 3, false}}}
+	want := "amy and bo share the win!\n\nFinal Scores:\n" +
+		"1=  amy" + strings.Repeat(" ", MaxNameChars-2) + "| 9 Glory in 5 turns\n" +
+		"1=  bo" + strings.Repeat(" ", MaxNameChars-1) + "| 9 Glory in 5 turns\n" +
+		"3   cy" + strings.Repeat(" ", MaxNameChars-1) + "| 3 Glory in 5 turns\n" +
+		"\nSeed: 4\n"
+	if got := r.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	r.Players = []PlayerResult{{"cy", 3, 5, 1, false}}
+	if got := r.headline(); got != "cy wins!" {
+		t.Errorf("headline %q", got)
+	}
+}
+
+// everyone counts each player's turns, and records the result once all the glory is in
+func TestTurnsAndResult(t *testing.T) {
+	w := newTestWorld(t)
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+	w.games[1].ResultsPath = path
+	// p1, p2 and p1 again start turns
+	for range 4 {
+		w.games[0].Conn.Send([]string{EndPhase})
+		w.sync()
+	}
+	for _, g := range w.games {
+		if g.Players["p1"].turns != 2 || g.Players["p2"].turns != 1 {
+			t.Fatalf("%s counted %d and %d turns", g.Name, g.Players["p1"].turns, g.Players["p2"].turns)
+		}
+	}
+	w.games[0].Conn.Send([]string{Glory, "p1", "7"})
+	w.sync()
+	if w.games[1].Result != nil {
+		t.Fatal("a result before everyone sent their glory")
+	}
+	w.games[1].Conn.Send([]string{Glory, "p2", "7"})
+	w.sync()
+	// p2 took fewer turns
+	want := []PlayerResult{{"p2", 7, 1, 1, false}, {"p1", 7, 2, 2, false}}
+	for _, g := range w.games {
+		if g.Result == nil || !slices.Equal(g.Result.Players, want) || g.Result.Rules != Rulesets[0].Name || len(g.Result.Kingdom) != KingdomSize {
+			t.Errorf("%s's result is %+v", g.Name, g.Result)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recorded GameResult
+	if err := json.Unmarshal(data, &recorded); err != nil || !slices.Equal(recorded.Players, want) {
+		t.Errorf("recorded %s", data)
+	}
+}
@@ -0,0 +1,90 @@
+// rulesets the host can pick, which decide how big the piles are and when the game ends
+package engine
+
+import (
+	"maps"
+	"strconv"
+)
+
+// ways to break a tie in glory, tried in order before the tied players share the win
+const (
+	// the player who took fewer turns wins
+	TieFewerTurns = iota
+)
+
+var TieBreakerNames = map[int]string{
+	TieFewerTurns: "fewer turns",
+}
+
+// how many cards start in the piles for some number of players
+type PileSizes struct {
+	// basic piles by card name
+	basic map[string]int
+	// kingdom piles, and kingdom piles of Glory cards
+	kingdom, glory int
+}
+
+type Ruleset struct {
+	Name string
+	// pile sizes for 2, 3, 4, 5 and 6 players, the last also used for more
+	sizes []PileSizes
+	// how many empty supply piles end the game
+	emptyPiles int
+	// the pile that ends the game as soon as it runs out
+	endPile string
+	// how ties in glory are broken, in order
+	tieBreakers []int
+}
+
+// starting amounts from the dominion wiki gameplay article
+var StandardSizes = []PileSizes{
+	{map[string]int{"Study": 46, "Prayer": 40, "Devotion": 30, "Temptation": 10, "Parable": 8, "Sermon": 8, "Miracle": 8}, 10, 8},
+	{map[string]int{"Study": 39, "Prayer": 40, "Devotion": 30, "Temptation": 20, "Parable": 12, "Sermon": 12, "Miracle": 12}, 10, 12},
+	{map[string]int{"Study": 32, "Prayer": 40, "Devotion": 30, "Temptation": 30, "Parable": 12, "Sermon": 12, "Miracle": 12}, 10, 12},
+	{map[string]int{"Study": 85, "Prayer": 80, "Devotion": 60, "Temptation": 40, "Parable": 12, "Sermon": 12, "Miracle": 15}, 10, 12},
+	{map[string]int{"Study": 78, "Prayer": 80, "Devotion": 60, "Temptation": 50, "Parable": 12, "Sermon": 12, "Miracle": 18}, 10, 12},
+}
+
+var Rulesets = []*Ruleset{
+	{"standard", StandardSizes, 3, "Miracle", []int{TieFewerTurns}},
+	{"quick game", scaledSizes(StandardSizes, 2, 3), 2, "Miracle", []int{TieFewerTurns}},
+	{"long game", scaledSizes(StandardSizes, 3, 2), 4, "Miracle", []int{TieFewerTurns}},
+}
+
+// returns the sizes with the Glory, Temptation and kingdom piles multiplied by num/den, so games
+// end sooner or later
+func scaledSizes(sizes []PileSizes, num, den int) []PileSizes {
+	scaled := make([]PileSizes, len(sizes))
+	for i, s := range sizes {
+		basic := maps.Clone(s.basic)
+		for _, name := range []string{"Temptation", "Parable", "Sermon", "Miracle"} {
+			basic[name] = basic[name] * num / den
+		}
+		scaled[i] = PileSizes{basic, s.kingdom * num / den, s.glory * num / den}
+	}
+	return scaled
+}
+
+// returns the ruleset with the given name, or the standard one if there is none
+func RulesetNamed(name string) *Ruleset {
+	for _, r := range Rulesets {
+		if r.Name == name {
+			return r
+		}
+	}
+	return Rulesets[0]
+}
+
+// returns the pile sizes for the number of players
+func (r *Ruleset) pileSizes(players int) PileSizes {
+	return r.sizes[min(max(players-2, 0), len(r.sizes)-1)]
+}
+
+// describes when the game ends and how ties are broken, for the lobby
+func (r *Ruleset) String() string {
+	msg := "ends when " + r.endPile + " runs out or " + strconv.Itoa(r.emptyPiles) + " piles are empty"
+	for _, t := range r.tieBreakers {
+		msg += ", ties broken by " + TieBreakerNames[t]
+	}
+	return msg
+}
@@ -0,0 +1,61 @@
+package engine
+
+import "testing"
+
+func TestRulesetNamed(t *testing.T) {
+	if r := RulesetNamed("quick game"); r != Rulesets[1] {
+		t.Errorf("quick game is %s", r.Name)
+	}
+	if r := RulesetNamed("nonsense"); r != Rulesets[0] {
+		t.Errorf("an unknown ruleset is %s, want the standard one", r.Name)
+	}
+}
+
+func TestPileSizes(t *testing.T) {
+	for _, tc := range []struct {
+		rules            string
+		players          int
+		miracle, kingdom int
+	}{
+		{"standard", 2, 8, 10},
+		{"standard", 4, 12, 10},
+		// more than six players use the six player sizes
+		{"standard", 8, 18, 10},
+		{"quick game", 2, 5, 6},
+		{"long game", 2, 12, 15},
+	} {
+		k := InitKingdom(KingdomPresets[0].Kingdom, tc.players, RulesetNamed(tc.rules))
+		if miracle, craft := len(k.pile("Miracle").cards), len(k.pile("Craft").cards); miracle != tc.miracle || craft != tc.kingdom {
+			t.Errorf("%s with %d players: %d Miracles and %d Crafts, want %d and %d", tc.rules, tc.players, miracle, craft, tc.miracle, tc.kingdom)
+		}
+	}
+}
+
+func TestGameDone(t *testing.T) {
+	for _, r := range Rulesets {
+		k := InitKingdom(KingdomPresets[0].Kingdom, 2, r)
+		piles := []string{"Shield", "Gift", "LostCoin", "Industry", "Craft"}
+		// one pile short of the empty piles that end the game
+		for _, name := range piles[:r.emptyPiles-1] {
+			k.pile(name).cards = nil
+		}
+		if k.GameDone() {
+			t.Errorf("%s: done with %d empty piles", r.Name, r.emptyPiles-1)
+		}
+		k.pile(piles[r.emptyPiles-1]).cards = nil
+		if !k.GameDone() {
+			t.Errorf("%s: not done with %d empty piles", r.Name, r.emptyPiles)
+		}
+		k = InitKingdom(KingdomPresets[0].Kingdom, 2, r)
+		k.pile(r.endPile).cards = nil
+		if !k.GameDone() {
+			t.Errorf("%s: not done once %s ran out", r.Name, r.endPile)
+		}
+	}
+}
+
+func TestRulesetString(t *testing.T) {
+	if got, want := RulesetNamed("long game").String(), "ends when Miracle runs out or 4 piles are empty, ties broken by fewer turns"; got != want {
+		t.Errorf("%q, want %q", got, want)
+	}
+}
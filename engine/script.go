@@ -0,0 +1,750 @@
+// a small language for card effects, so cards from the card data file and packs can do more than
+// add stats. a script is a list of commands, one per line:
+//
+//	draw N                   draw N cards
+//	gain CARD [to PLACE]     gain a card if there are any left (PLACE is hand, deck or discard)
+//	choose FROM [...] [as X] pick a card from hand, revealed, discard or gain (the kingdom), keeping it in X
+//	release X|revealed|aside release the chosen card, or all revealed or set aside cards
+//	discard X|revealed|aside discard the chosen card, or all revealed or set aside cards
+//	topdeck X|revealed|aside put the chosen card, or all revealed or set aside cards, on the deck
+//	setaside X|revealed      set the chosen card, or all revealed cards, aside
+//	keep X|revealed          put the chosen card, or all revealed cards, into the hand
+//	play X [N]               play the chosen card N times, once if left out, without using up a
+//	                         work, ending the script
+//	reveal N|hand|X          reveal the top N cards of the deck, the hand or the chosen card
+//	look N                   take the top N cards of the deck to choose from, without revealing them
+//	works|blessings|faith N  add to the turn
+//	affect others            run the others section for each other player
+//	distribute CARD          give a card to each other player not blocking the trial, in turn order
+//	wait                     wait until every other player has decided
+//	if COND / else / end     COND is X, revealed, hand, or N > N, N < N or N = N, after an optional not
+//	while COND / end
+//	repeat N / end
+//	stop                     end the script
+//
+// a number N can be a whole number, hand (cards in hand), empty (empty Verse piles), revealed,
+// discarded (cards the script discarded) or X.cost, plus or minus a whole number, e.g. x.cost+2. choose takes type T, name CARD, not CARD,
+// cost N (the most it can cost), to PLACE (for gain), for COMMAND (what the card is picked for, which
+// is release, discard, topdeck, setaside or play) and optional. a choice with no cards to pick from
+// leaves X empty, and a choice that isn't optional and has one card to pick is made for you. looked
+// at cards count as revealed, and discard offers one of each card in the discard.
+//
+// the lines after play: run for the player who played the card, which is where a script starts.
+// the lines after others: run for each other player when it affects them, and the lines after
+// trial: run for each other player who doesn't block it. revealed and set aside cards left at the
+// end are discarded
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// the parts of a script, run by different players
+const (
+	SectionPlay = iota
+	SectionOthers
+	SectionTrial
+)
+
+var SectionNames = map[string]int{"play:": SectionPlay, "others:": SectionOthers, "trial:": SectionTrial}
+
+// where a card is chosen from
+const (
+	FromHand = iota
+	FromRevealed
+	FromKingdom
+	FromDiscard
+	// the card has been moved since it was chosen
+	FromNowhere
+)
+
+var FromNames = map[string]int{"hand": FromHand, "revealed": FromRevealed, "gain": FromKingdom, "discard": FromDiscard}
+
+// what a card can be chosen for, and how prompts say it
+var ForNames = map[string]string{"release": "to release", "discard": "to discard", "topdeck": "to put on top of your deck", "setaside": "to set aside", "play": "to play"}
+
+var ToNames = map[string]int{"hand": ToHand, "deck": ToDeck, "discard": ToDiscard}
+
+// how many commands a script can run, counting across its waits, so a broken script can't hang the game
+const MaxScriptSteps = 1000
+
+// a card's effect, parsed from its script lines
+type Script struct {
+	sections map[int][]Instr
+}
+
+// one command of a script
+type Instr struct {
+	op string
+	// the variable, card name or place the command works on
+	arg string
+	// how many, for commands that take a number
+	n Expr
+	// for if and while
+	cond Cond
+	// for choose
+	from     int
+	filter   CardFilter
+	optional bool
+	as       string
+	// what the card is chosen for, one of ForNames
+	purpose string
+	// where gained cards go
+	to int
+	// where if, else, while, repeat and end go next
+	jump int
+}
+
+// a number worked out when the command runs: n plus the value of base
+type Expr struct {
+	base string
+	n    int
+}
+
+// a test for if and while. without an op it tests whether word is set or not empty
+type Cond struct {
+	not  bool
+	word string
+	a, b Expr
+	op   string
+}
+
+// which cards a choice allows
+type CardFilter struct {
+	// -1 for any type
+	cardType     int
+	name, except string
+	// the most it can cost, if limited
+	cost    Expr
+	hasCost bool
+}
+
+// a chosen card and where it was chosen from
+type ScriptVar struct {
+	c    *Card
+	from int
+}
+
+// a script running on this client, which can stop to wait for a choice or for other players
+type ScriptRun struct {
+	c       *Card
+	section int
+	pc      int
+	vars    map[string]ScriptVar
+	// how many times each repeat we are in has left to go
+	loops []int
+	// whether it is waiting for the other players to decide
+	waiting bool
+	// how many cards it drew, to tell everyone
+	drawn int
+	// how many cards it discarded
+	discarded int
+	// how many commands it has run
+	steps int
+}
+
+// returns a copy that can run on without changing this one
+func (run *ScriptRun) clone() *ScriptRun {
+	c := *run
+	c.vars = maps.Clone(run.vars)
+	c.loops = slices.Clone(run.loops)
+	return &c
+}
+
+// the command the script is at
+func (run *ScriptRun) instr() *Instr {
+	return &run.c.script.sections[run.section][run.pc]
+}
+
+// returns true if the script has the section, even if it's empty
+func (s *Script) has(section int) bool {
+	if s == nil {
+		return false
+	}
+	_, ok := s.sections[section]
+	return ok
+}
+
+// returns the names of the cards the script gains or gives out
+func (s *Script) cardsNamed() []string {
+	if s == nil {
+		return nil
+	}
+	var names []string
+	for _, code := range s.sections {
+		for _, in := range code {
+			if in.op == "gain" || in.op == "distribute" {
+				names = append(names, in.arg)
+			}
+		}
+	}
+	return names
+}
+
+// parses script lines, reporting the first line that doesn't make sense
+func parseScript(lines []string) (*Script, error) {
+	s := &Script{sections: map[int][]Instr{SectionPlay: nil}}
+	section := SectionPlay
+	// the if, else, while and repeat commands still waiting for their end
+	var open []int
+	for i, line := range lines {
+		line, _, _ = strings.Cut(line, "#")
+		words := strings.Fields(line)
+		if len(words) == 0 {
+			continue
+		}
+		if sec, ok := SectionNames[words[0]]; ok && len(words) == 1 {
+			if len(open) > 0 {
+				return nil, fmt.Errorf("line %d: %s is missing its end", i+1, s.sections[section][open[0]].op)
+			}
+			section = sec
+			// an empty section still counts, e.g. a trial that only needs blocking
+			if _, ok := s.sections[sec]; !ok {
+				s.sections[sec] = nil
+			}
+			continue
+		}
+		in, err := parseInstr(words)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		code := s.sections[section]
+		pc := len(code)
+		switch in.op {
+		case "if", "while", "repeat":
+			open = append(open, pc)
+		case "else":
+			if len(open) == 0 || code[open[len(open)-1]].op != "if" {
+				return nil, fmt.Errorf("line %d: else without if", i+1)
+			}
+			// a false if goes to the line after else
+			code[open[len(open)-1]].jump = pc
+			open[len(open)-1] = pc
+		case "end":
+			if len(open) == 0 {
+				return nil, fmt.Errorf("line %d: end without if, while or repeat", i+1)
+			}
+			start := open[len(open)-1]
+			open = open[:len(open)-1]
+			code[start].jump = pc
+			in.jump = start
+		}
+		s.sections[section] = append(code, in)
+	}
+	if len(open) > 0 {
+		return nil, errors.New(s.sections[section][open[0]].op + " is missing its end")
+	}
+	return s, nil
+}
+
+// parses one command from its words
+func parseInstr(words []string) (Instr, error) {
+	in := Instr{op: words[0], filter: CardFilter{cardType: -1}, to: ToDiscard}
+	args := words[1:]
+	var err error
+	switch in.op {
+	case "draw", "works", "blessings", "faith", "repeat":
+		if len(args) != 1 {
+			return in, errors.New(in.op + " needs a number")
+		}
+		in.n, err = parseExpr(args[0])
+	case "gain":
+		if len(args) == 0 {
+			return in, errors.New("gain needs a card")
+		}
+		in.arg = args[0]
+		err = parseOptions(&in, args[1:])
+	case "choose":
+		if len(args) == 0 {
+			return in, errors.New("choose needs hand, revealed or gain")
+		}
+		from, ok := FromNames[args[0]]
+		if !ok {
+			return in, errors.New("can't choose from " + args[0])
+		}
+		in.from = from
+		err = parseOptions(&in, args[1:])
+	case "release", "discard", "topdeck", "setaside", "keep", "distribute":
+		if len(args) != 1 {
+			return in, errors.New(in.op + " needs one card")
+		}
+		in.arg = args[0]
+	case "play":
+		if len(args) != 1 && len(args) != 2 {
+			return in, errors.New("play needs one card, and how many times if more than once")
+		}
+		in.arg, in.n = args[0], Expr{n: 1}
+		if len(args) == 2 {
+			in.n, err = parseExpr(args[1])
+		}
+	case "reveal":
+		if len(args) != 1 {
+			return in, errors.New("reveal needs a number, hand or a card")
+		}
+		// a number reveals from the deck, anything else is hand or a variable
+		if n, err := parseExpr(args[0]); err == nil && args[0] != "hand" {
+			in.n = n
+		} else {
+			in.arg = args[0]
+		}
+	case "look":
+		if len(args) != 1 {
+			return in, errors.New("look needs a number")
+		}
+		in.n, err = parseExpr(args[0])
+	case "affect":
+		if len(args) != 1 || args[0] != "others" {
+			return in, errors.New("affect needs others")
+		}
+	case "if", "while":
+		in.cond, err = parseCond(args)
+	case "else", "end", "stop", "wait":
+		if len(args) != 0 {
+			return in, errors.New(in.op + " takes nothing after it")
+		}
+	default:
+		return in, errors.New("unknown command " + in.op)
+	}
+	return in, err
+}
+
+// parses what comes after gain and choose
+func parseOptions(in *Instr, args []string) error {
+	for i := 0; i < len(args); i++ {
+		if args[i] == "optional" {
+			in.optional = true
+			continue
+		}
+		if i+1 == len(args) {
+			return errors.New(args[i] + " needs something after it")
+		}
+		value := args[i+1]
+		switch args[i] {
+		case "type":
+			if in.filter.cardType = cardType(value); in.filter.cardType == -1 {
+				return errors.New("unknown type " + value)
+			}
+		case "name":
+			in.filter.name = value
+		case "not":
+			in.filter.except = value
+		case "cost":
+			n, err := parseExpr(value)
+			if err != nil {
+				return err
+			}
+			in.filter.cost, in.filter.hasCost = n, true
+		case "to":
+			to, ok := ToNames[value]
+			if !ok {
+				return errors.New("can't gain to " + value)
+			}
+			in.to = to
+		case "as":
+			in.as = value
+		case "for":
+			if _, ok := ForNames[value]; !ok {
+				return errors.New("can't choose a card for " + value)
+			}
+			in.purpose = value
+		default:
+			return errors.New("unknown option " + args[i])
+		}
+		i++
+	}
+	return nil
+}
+
+// parses a number like 3, hand, empty or x.cost+2
+func parseExpr(s string) (Expr, error) {
+	base, offset := s, ""
+	if i := strings.LastIndexAny(s, "+-"); i > 0 {
+		base, offset = s[:i], s[i:]
+	}
+	var e Expr
+	if n, err := strconv.Atoi(base); err == nil {
+		e.n = n
+	} else if base == "hand" || base == "empty" || base == "revealed" || base == "discarded" || strings.HasSuffix(base, ".cost") {
+		e.base = base
+	} else {
+		return e, errors.New("not a number: " + s)
+	}
+	if offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil {
+			return e, errors.New("not a number: " + s)
+		}
+		e.n += n
+	}
+	return e, nil
+}
+
+// parses a test like x, not revealed or hand > 3
+func parseCond(args []string) (Cond, error) {
+	var c Cond
+	if len(args) > 0 && args[0] == "not" {
+		c.not = true
+		args = args[1:]
+	}
+	switch len(args) {
+	case 1:
+		c.word = args[0]
+		return c, nil
+	case 3:
+		if !slices.Contains([]string{">", "<", "="}, args[1]) {
+			return c, errors.New("unknown comparison " + args[1])
+		}
+		c.op = args[1]
+		var err error
+		if c.a, err = parseExpr(args[0]); err != nil {
+			return c, err
+		}
+		c.b, err = parseExpr(args[2])
+		return c, err
+	}
+	return c, errors.New("if and while need a variable or a comparison")
+}
+
+// starts running a section of a card's script on this client
+func (g *Game) runScript(c *Card, section int) {
+	g.script = &ScriptRun{c: c, section: section, vars: make(map[string]ScriptVar)}
+	g.resumeScript()
+}
+
+// runs the script until it needs a choice, waits for other players, or ends
+func (g *Game) resumeScript() {
+	run := g.script
+	run.waiting = false
+	code := run.c.script.sections[run.section]
+	for ; run.pc < len(code); run.steps++ {
+		if run.steps == MaxScriptSteps {
+			log.Println(run.c.Name + " ran too many commands, stopping it")
+			break
+		}
+		if !g.step(run, &code[run.pc]) {
+			return
+		}
+	}
+	g.endScript()
+	g.playAgain()
+}
+
+// runs one command and moves on to the next, or returns false if the script has to stop and wait
+func (g *Game) step(run *ScriptRun, in *Instr) bool {
+	code := run.c.script.sections[run.section]
+	switch in.op {
+	case "draw":
+		n := len(g.MyCards.Hand)
+		g.MyCards.Hand = g.draw(g.eval(run, in.n), g.MyCards.Hand)
+		run.drawn += len(g.MyCards.Hand) - n
+	case "gain":
+		if p := g.Kingdom.PileWithTop(in.arg); p != nil {
+			g.gainCard(p.Top(), in.to)
+		}
+	case "choose":
+		options := g.scriptOptions(run, in)
+		switch {
+		case len(options) == 0:
+			run.vars[in.as] = ScriptVar{from: FromNowhere}
+		case len(options) == 1 && !in.optional:
+			g.choose(run, in, options[0])
+		default:
+			g.Decision = DecisionScript
+			return false
+		}
+	case "release":
+		cards := g.takeScriptCards(run, in.arg)
+		if len(cards) > 0 {
+			g.Conn.Send(append([]string{Released, g.Name}, CardNames(cards)...))
+		}
+	case "discard":
+		if cards := g.takeScriptCards(run, in.arg); len(cards) > 0 {
+			g.discardPublicly(cards...)
+			run.discarded += len(cards)
+		}
+	case "topdeck":
+		g.MyCards.putOnDeck(g.takeScriptCards(run, in.arg)...)
+	case "setaside":
+		g.MyCards.setAside(g.takeScriptCards(run, in.arg)...)
+	case "keep":
+		if cards := g.takeScriptCards(run, in.arg); len(cards) > 0 {
+			g.MyCards.Hand = append(g.MyCards.Hand, cards...)
+			sortCards(g.MyCards.Hand)
+		}
+	case "play":
+		if cards := g.takeScriptCards(run, in.arg); len(cards) > 0 {
+			// the card may have a script of its own, so this one ends first. it is played again once
+			// it is done, before anything else waiting to be played again
+			g.endScript()
+			g.replays = slices.Concat(slices.Repeat(cards, max(g.eval(run, in.n)-1, 0)), g.replays)
+			g.localCardEffect(cards[0], FreePlay)
+			return false
+		}
+	case "look":
+		g.MyCards.decision = g.draw(g.eval(run, in.n), g.MyCards.decision)
+	case "reveal":
+		var cards []*Card
+		switch in.arg {
+		case "":
+			n := len(g.MyCards.decision)
+			g.MyCards.decision = g.draw(g.eval(run, in.n), g.MyCards.decision)
+			cards = g.MyCards.decision[n:]
+		case "hand":
+			cards = g.MyCards.Hand
+		default:
+			if v := run.vars[in.arg]; v.c != nil {
+				cards = []*Card{v.c}
+			}
+		}
+		g.Conn.Send(append([]string{Revealed, g.Name}, CardNames(cards)...))
+	case "works", "blessings", "faith":
+		g.Conn.Send([]string{AddedStats, g.Name, in.op, strconv.Itoa(g.eval(run, in.n))})
+	case "affect":
+		g.affectOthers(run.c)
+	case "distribute":
+		if c, ok := CardNameMap[in.arg]; ok {
+			var players []string
+			for _, name := range g.playersLeftOf(g.Name) {
+				if !slices.Contains(g.unaffected, name) {
+					players = append(players, name)
+				}
+			}
+			g.distribute(c, players)
+		}
+	case "wait":
+		if g.OtherDecisions > 0 {
+			run.waiting = true
+			return false
+		}
+	case "if", "while":
+		if !g.test(run, in.cond) {
+			run.pc = in.jump + 1
+			return true
+		}
+	case "repeat":
+		n := g.eval(run, in.n)
+		if n <= 0 {
+			run.pc = in.jump + 1
+			return true
+		}
+		run.loops = append(run.loops, n)
+	case "else":
+		// the if part ran, so skip the else part
+		run.pc = in.jump + 1
+		return true
+	case "end":
+		switch code[in.jump].op {
+		case "while":
+			run.pc = in.jump
+			return true
+		case "repeat":
+			last := len(run.loops) - 1
+			if run.loops[last]--; run.loops[last] > 0 {
+				run.pc = in.jump + 1
+				return true
+			}
+			run.loops = run.loops[:last]
+		}
+	case "stop":
+		run.pc = len(code)
+		return true
+	}
+	run.pc++
+	return true
+}
+
+// finishes the running script, telling everyone if it was run because another player's card affected us
+func (g *Game) endScript() {
+	run := g.script
+	g.script = nil
+	g.Decision = -1
+	if revealed := g.MyCards.decision; len(revealed) > 0 {
+		g.MyCards.decision = nil
+		g.discardPublicly(revealed...)
+	}
+	if aside := g.MyCards.aside; len(aside) > 0 {
+		g.MyCards.aside = nil
+		g.discardPublicly(aside...)
+	}
+	if run.section != SectionPlay {
+		g.Conn.Send([]string{Affected, g.Name, run.c.Name, strconv.Itoa(run.drawn)})
+	}
+}
+
+// the cards the current choose command allows
+func (g *Game) scriptOptions(run *ScriptRun, in *Instr) []*Card {
+	var cards []*Card
+	switch in.from {
+	case FromHand:
+		cards = g.MyCards.Hand
+	case FromRevealed:
+		cards = g.MyCards.decision
+	case FromKingdom:
+		// only the top cards of supply piles can be chosen
+		for _, p := range g.Kingdom.Piles {
+			if c := p.Top(); c != nil && p.supply {
+				cards = append(cards, c)
+			}
+		}
+	case FromDiscard:
+		cards = slices.Clone(g.MyCards.Discard)
+		sortCards(cards)
+	}
+	var options []*Card
+	for _, c := range cards {
+		f := in.filter
+		switch {
+		case f.cardType != -1 && !slices.Contains(c.CardTypes, f.cardType):
+		case f.name != "" && c.Name != f.name:
+		case f.except != "" && c.Name == f.except:
+		case f.hasCost && c.Cost > g.eval(run, f.cost):
+		case slices.Contains(options, c):
+		default:
+			options = append(options, c)
+		}
+	}
+	return options
+}
+
+// what runs when a card is picked for the current choose command, or nil if the player skipped it
+func (g *Game) scriptChose(c *Card) {
+	run := g.script
+	g.Decision = -1
+	g.choose(run, run.instr(), c)
+	run.pc++
+	g.resumeScript()
+}
+
+// keeps the chosen card in the choose command's variable, gaining it if it was chosen from the kingdom
+func (g *Game) choose(run *ScriptRun, in *Instr, c *Card) {
+	if c == nil {
+		run.vars[in.as] = ScriptVar{from: FromNowhere}
+		return
+	}
+	run.vars[in.as] = ScriptVar{c, in.from}
+	if in.from == FromKingdom {
+		g.gainCard(c, in.to)
+	}
+}
+
+// takes the card in a variable, or all revealed or set aside cards, out of where they are
+func (g *Game) takeScriptCards(run *ScriptRun, arg string) []*Card {
+	switch arg {
+	case "revealed":
+		cards := g.MyCards.decision
+		g.MyCards.decision = nil
+		return cards
+	case "aside":
+		cards := g.MyCards.aside
+		g.MyCards.aside = nil
+		return cards
+	}
+	v := run.vars[arg]
+	var from *[]*Card
+	switch v.from {
+	case FromHand:
+		from = &g.MyCards.Hand
+	case FromRevealed:
+		from = &g.MyCards.decision
+	case FromDiscard:
+		from = &g.MyCards.Discard
+	default:
+		return nil
+	}
+	i := slices.Index(*from, v.c)
+	if i == -1 {
+		return nil
+	}
+	*from = slices.Delete(*from, i, i+1)
+	// the variable keeps the card so its cost can still be used
+	run.vars[arg] = ScriptVar{v.c, FromNowhere}
+	return []*Card{v.c}
+}
+
+// works out a number
+func (g *Game) eval(run *ScriptRun, e Expr) int {
+	switch e.base {
+	case "":
+		return e.n
+	case "hand":
+		return len(g.MyCards.Hand) + e.n
+	case "revealed":
+		return len(g.MyCards.decision) + e.n
+	case "discarded":
+		return run.discarded + e.n
+	case "empty":
+		return g.Kingdom.emptyPiles() + e.n
+	}
+	if v := run.vars[strings.TrimSuffix(e.base, ".cost")]; v.c != nil {
+		return v.c.Cost + e.n
+	}
+	return e.n
+}
+
+// works out a test for if and while
+func (g *Game) test(run *ScriptRun, c Cond) bool {
+	var result bool
+	switch c.op {
+	case "":
+		switch c.word {
+		case "hand":
+			result = len(g.MyCards.Hand) > 0
+		case "revealed":
+			result = len(g.MyCards.decision) > 0
+		default:
+			result = run.vars[c.word].c != nil
+		}
+	case ">":
+		result = g.eval(run, c.a) > g.eval(run, c.b)
+	case "<":
+		result = g.eval(run, c.a) < g.eval(run, c.b)
+	case "=":
+		result = g.eval(run, c.a) == g.eval(run, c.b)
+	}
+	return result != c.not
+}
+
+// describes the current choose command, e.g. "You may select a Faith card from your hand", and
+// whether it can be skipped
+func (g *Game) promptScriptChoice() (string, bool) {
+	run := g.script
+	in := run.instr()
+	msg := "Select a card"
+	if in.optional {
+		msg = "You may select a card"
+	}
+	if in.filter.name != "" {
+		msg = strings.Replace(msg, "card", in.filter.name, 1)
+	} else if in.filter.cardType != -1 {
+		msg = strings.Replace(msg, "card", CardTypeNames[in.filter.cardType]+" card", 1)
+	}
+	switch in.from {
+	case FromHand:
+		msg += " from your hand"
+	case FromRevealed:
+		msg += " you revealed"
+	case FromDiscard:
+		msg += " from your discard"
+	case FromKingdom:
+		msg += " to gain"
+		switch in.to {
+		case ToHand:
+			msg += " to your hand"
+		case ToDeck:
+			msg += " onto your deck"
+		}
+	}
+	if in.filter.hasCost {
+		msg += " costing up to " + strconv.Itoa(g.eval(run, in.filter.cost)) + " Faith"
+	}
+	if in.purpose != "" {
+		msg += " " + ForNames[in.purpose]
+	}
+	return msg, in.optional
+}
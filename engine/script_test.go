@@ -0,0 +1,519 @@
+package engine
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// a started game between two players that nobody plays, with p1 to move
+func newTestWorld(t *testing.T) *World {
+	t.Helper()
+	sc := &SimConfig{kingdom: KingdomPresets[0].Kingdom, rules: Rulesets[0], strategies: []Strategy{nil, nil}}
+	w := sc.start(1, 0)
+	w.sync()
+	if g := w.games[0]; g.State != Playing || g.TurnModulus[0] != g.Name {
+		t.Fatal("p1 should be playing first")
+	}
+	return w
+}
+
+// has every game handle the messages sent so far, and any they send in answer
+func (w *World) sync() {
+	for caughtUp := false; !caughtUp; {
+		caughtUp = true
+		for i, g := range w.games {
+			for !w.conns[i].caughtUp() {
+				g.handleMessage(w.conns[i].Receive())
+				caughtUp = false
+			}
+		}
+	}
+}
+
+// sets our cards, the top of the deck last
+func setCards(g *Game, hand, deck, discard []*Card) {
+	g.MyCards.Hand, g.MyCards.Deck, g.MyCards.Discard = hand, deck, discard
+}
+
+// a card with the script, which messages can name until the test ends
+func scriptCard(t *testing.T, lines ...string) *Card {
+	t.Helper()
+	s, err := parseScript(lines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Card{Name: "Test", CardTypes: []int{WorkType}, script: s}
+	CardNameMap[c.Name] = c
+	t.Cleanup(func() { delete(CardNameMap, c.Name) })
+	return c
+}
+
+// runs the card's script for p1 and lets everyone see what it did
+func runTestScript(w *World, c *Card) *Game {
+	g := w.games[0]
+	g.runScript(c, SectionPlay)
+	w.sync()
+	return g
+}
+
+func count(cards []*Card, c *Card) int {
+	_, same := where(cards, func(d *Card) bool { return d == c })
+	return len(same)
+}
+
+func TestParseScriptErrors(t *testing.T) {
+	for _, tc := range []struct {
+		lines []string
+		err   string
+	}{
+		{[]string{"jump 3"}, "line 1: unknown command jump"},
+		{[]string{"draw"}, "line 1: draw needs a number"},
+		{[]string{"draw lots"}, "line 1: not a number: lots"},
+		{[]string{"faith 1", "look"}, "line 2: look needs a number"},
+		{[]string{"gain"}, "line 1: gain needs a card"},
+		{[]string{"gain Prayer to attic"}, "line 1: can't gain to attic"},
+		{[]string{"choose"}, "line 1: choose needs hand, revealed or gain"},
+		{[]string{"choose pocket"}, "line 1: can't choose from pocket"},
+		{[]string{"choose hand type Sin"}, "line 1: unknown type Sin"},
+		{[]string{"choose hand for eating"}, "line 1: can't choose a card for eating"},
+		{[]string{"choose hand as"}, "line 1: as needs something after it"},
+		{[]string{"choose hand quickly x"}, "line 1: unknown option quickly"},
+		{[]string{"release"}, "line 1: release needs one card"},
+		{[]string{"play x 2 3"}, "line 1: play needs one card, and how many times if more than once"},
+		{[]string{"play x twice"}, "line 1: not a number: twice"},
+		{[]string{"reveal"}, "line 1: reveal needs a number, hand or a card"},
+		{[]string{"affect me"}, "line 1: affect needs others"},
+		{[]string{"stop now"}, "line 1: stop takes nothing after it"},
+		{[]string{"if hand ~ 3", "end"}, "line 1: unknown comparison ~"},
+		{[]string{"if", "end"}, "line 1: if and while need a variable or a comparison"},
+		{[]string{"else"}, "line 1: else without if"},
+		{[]string{"while x", "else", "end"}, "line 2: else without if"},
+		{[]string{"end"}, "line 1: end without if, while or repeat"},
+		{[]string{"repeat 2", "faith 1"}, "repeat is missing its end"},
+		{[]string{"if x", "trial:", "end"}, "line 2: if is missing its end"},
+	} {
+		_, err := parseScript(tc.lines)
+		if err == nil || err.Error() != tc.err {
+			t.Errorf("parseScript(%q) = %v, want %s", tc.lines, err, tc.err)
+		}
+	}
+}
+
+func TestParseScriptSections(t *testing.T) {
+	s, err := parseScript([]string{"gain Devotion # comment", "", "trial:", "others:", "draw 1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.has(SectionPlay) || !s.has(SectionTrial) || !s.has(SectionOthers) {
+		t.Error("every section should be there, even the empty trial")
+	}
+	if len(s.sections[SectionPlay]) != 1 || len(s.sections[SectionTrial]) != 0 || len(s.sections[SectionOthers]) != 1 {
+		t.Errorf("sections = %v", s.sections)
+	}
+	if names := s.cardsNamed(); !slices.Equal(names, []string{"Devotion"}) {
+		t.Errorf("cardsNamed() = %v", names)
+	}
+}
+
+func TestScriptDraw(t *testing.T) {
+	w := newTestWorld(t)
+	setCards(w.games[0], nil, []*Card{Study, Prayer}, nil)
+	g := runTestScript(w, scriptCard(t, "draw 1"))
+	if !slices.Equal(g.MyCards.Hand, []*Card{Prayer}) || !slices.Equal(g.MyCards.Deck, []*Card{Study}) {
+		t.Errorf("hand %v, deck %v", CardNames(g.MyCards.Hand), CardNames(g.MyCards.Deck))
+	}
+}
+
+func TestScriptGain(t *testing.T) {
+	w := newTestWorld(t)
+	setCards(w.games[0], nil, nil, nil)
+	left := len(w.games[0].Kingdom.PileWithTop(Prayer.Name).cards)
+	g := runTestScript(w, scriptCard(t, "gain Prayer to hand", "gain Prayer to deck", "gain Prayer"))
+	if !slices.Equal(g.MyCards.Hand, []*Card{Prayer}) || !slices.Equal(g.MyCards.Deck, []*Card{Prayer}) || !slices.Equal(g.MyCards.Discard, []*Card{Prayer}) {
+		t.Errorf("hand %v, deck %v, discard %v", CardNames(g.MyCards.Hand), CardNames(g.MyCards.Deck), CardNames(g.MyCards.Discard))
+	}
+	for _, o := range w.games {
+		if n := len(o.Kingdom.PileWithTop(Prayer.Name).cards); n != left-3 {
+			t.Errorf("%s sees %d Prayers left, want %d", o.Name, n, left-3)
+		}
+	}
+}
+
+func TestScriptGainStopsWhenPileRunsOut(t *testing.T) {
+	w := newTestWorld(t)
+	for _, g := range w.games {
+		p := g.Kingdom.PileWithTop(Miracle.Name)
+		p.cards = p.cards[:2]
+	}
+	setCards(w.games[0], nil, nil, nil)
+	g := runTestScript(w, scriptCard(t, "repeat 3", "gain Miracle", "end"))
+	if n := count(g.MyCards.Discard, Miracle); n != 2 {
+		t.Errorf("gained %d Miracles from a pile of 2", n)
+	}
+	if n := count(g.Players[g.Name].cards, Miracle); n != 2 {
+		t.Errorf("everyone saw %d Miracles gained", n)
+	}
+}
+
+func TestScriptChooseGain(t *testing.T) {
+	w := newTestWorld(t)
+	setCards(w.games[0], nil, nil, nil)
+	g := w.games[0]
+	g.runScript(scriptCard(t, "choose gain cost 3 type Faith to hand as x", "faith x.cost"), SectionPlay)
+	if g.Decision != DecisionScript {
+		t.Fatal("should be choosing")
+	}
+	if choices := g.decisionChoices(); !slices.Equal(choices, []*Card{Study, Prayer}) {
+		t.Errorf("choices %v, want Study and Prayer", CardNames(choices))
+	}
+	if msg, skippable := g.PromptDecision(); msg != "Select a Faith card to gain to your hand costing up to 3 Faith" || skippable {
+		t.Errorf("prompt %q, %v", msg, skippable)
+	}
+	g.decide(Prayer)
+	w.sync()
+	if !slices.Equal(g.MyCards.Hand, []*Card{Prayer}) || g.Stats.Faith != 3 || g.Decision != -1 {
+		t.Errorf("hand %v, faith %d, decision %d", CardNames(g.MyCards.Hand), g.Stats.Faith, g.Decision)
+	}
+}
+
+func TestScriptChooseOneIsMadeForYou(t *testing.T) {
+	w := newTestWorld(t)
+	setCards(w.games[0], []*Card{Prayer, Study}, nil, nil)
+	g := runTestScript(w, scriptCard(t, "choose hand name Prayer as x", "topdeck x"))
+	if !slices.Equal(g.MyCards.Deck, []*Card{Prayer}) || !slices.Equal(g.MyCards.Hand, []*Card{Study}) {
+		t.Errorf("deck %v, hand %v", CardNames(g.MyCards.Deck), CardNames(g.MyCards.Hand))
+	}
+}
+
+func TestScriptChooseOptional(t *testing.T) {
+	w := newTestWorld(t)
+	setCards(w.games[0], []*Card{Prayer}, nil, nil)
+	g := w.games[0]
+	g.runScript(scriptCard(t, "choose hand optional for release as x", "if x", "faith 1", "else", "works 1", "end"), SectionPlay)
+	if msg, skippable := g.PromptDecision(); msg != "You may select a card from your hand to release" || !skippable {
+		t.Errorf("prompt %q, %v", msg, skippable)
+	}
+	works := g.Stats.Works
+	g.skipDecision()
+	w.sync()
+	if g.Stats.Works != works+1 || g.Stats.Faith != 0 {
+		t.Errorf("skipping should run the else part, works %d faith %d", g.Stats.Works, g.Stats.Faith)
+	}
+}
+
+func TestScriptChooseDiscard(t *testing.T) {
+	w := newTestWorld(t)
+	setCards(w.games[0], nil, nil, []*Card{Study, Prayer, Study})
+	g := w.games[0]
+	g.runScript(scriptCard(t, "choose discard for topdeck as x", "topdeck x"), SectionPlay)
+	if row := g.DecisionRow(); !slices.Equal(row, []*Card{Prayer, Study}) {
+		t.Errorf("one of each card should be shown, not %v", CardNames(row))
+	}
+	g.decide(Study)
+	w.sync()
+	if !slices.Equal(g.MyCards.Deck, []*Card{Study}) || !slices.Equal(g.MyCards.Discard, []*Card{Prayer, Study}) {
+		t.Errorf("deck %v, discard %v", CardNames(g.MyCards.Deck), CardNames(g.MyCards.Discard))
+	}
+}
+
+func TestScriptRelease(t *testing.T) {
+	w := newTestWorld(t)
+	setCards(w.games[0], []*Card{Parable}, nil, nil)
+	g := runTestScript(w, scriptCard(t, "choose hand as x", "release x"))
+	if len(g.MyCards.Hand) != 0 {
+		t.Error("the card should leave the hand")
+	}
+	for _, o := range w.games {
+		if !slices.Equal(o.Kingdom.Released, []*Card{Parable}) {
+			t.Errorf("%s sees %v released", o.Name, CardNames(o.Kingdom.Released))
+		}
+	}
+}
+
+func TestScriptRevealAndDiscard(t *testing.T) {
+	w := newTestWorld(t)
+	setCards(w.games[0], nil, []*Card{Study, Prayer, Devotion}, nil)
+	g := runTestScript(w, scriptCard(t, "reveal 2", "discard revealed"))
+	if !slices.Equal(g.MyCards.Deck, []*Card{Study}) || count(g.MyCards.Discard, Prayer) != 1 || count(g.MyCards.Discard, Devotion) != 1 {
+		t.Errorf("deck %v, discard %v", CardNames(g.MyCards.Deck), CardNames(g.MyCards.Discard))
+	}
+	if log := strings.Join(w.games[1].ActionLog, "\n"); !strings.Contains(log, "p1 revealed Devotion, Prayer") || !strings.Contains(log, "p1 discarded") {
+		t.Errorf("p2 should see the reveal and the discard:\n%s", log)
+	}
+}
+
+func TestScriptRevealHand(t *testing.T) {
+	w := newTestWorld(t)
+	setCards(w.games[0], []*Card{Study}, nil, nil)
+	runTestScript(w, scriptCard(t, "reveal hand"))
+	if log := w.games[1].ActionLog; log[len(log)-1] != "p1 revealed Study" {
+		t.Errorf("last log line %q", log[len(log)-1])
+	}
+}
+
+func TestScriptLookKeepAndSetAside(t *testing.T) {
+	w := newTestWorld(t)
+	setCards(w.games[0], nil, []*Card{Study, Prayer, Devotion}, nil)
+	g := w.games[0]
+	g.runScript(scriptCard(t, "look 2", "choose revealed for setaside as x", "setaside x", "keep revealed", "look 1"), SectionPlay)
+	g.decide(Devotion)
+	w.sync()
+	if !slices.Equal(g.MyCards.Hand, []*Card{Prayer}) || len(g.MyCards.Deck) != 0 {
+		t.Errorf("hand %v, deck %v", CardNames(g.MyCards.Hand), CardNames(g.MyCards.Deck))
+	}
+	if slices.ContainsFunc(w.games[1].ActionLog, func(s string) bool { return strings.Contains(s, "revealed") }) {
+		t.Error("looking shouldn't reveal")
+	}
+	// what is left looked at and set aside is discarded at the end
+	if len(g.MyCards.aside) != 0 || !slices.Equal(g.MyCards.Discard, []*Card{Study, Devotion}) {
+		t.Errorf("aside %v, discard %v", CardNames(g.MyCards.aside), CardNames(g.MyCards.Discard))
+	}
+}
+
+func TestScriptTopdeckAside(t *testing.T) {
+	w := newTestWorld(t)
+	setCards(w.games[0], nil, []*Card{Study, Prayer}, nil)
+	g := runTestScript(w, scriptCard(t, "look 2", "choose revealed name Study as x", "setaside x", "topdeck revealed", "topdeck aside"))
+	if !slices.Equal(g.MyCards.Deck, []*Card{Prayer, Study}) {
+		t.Errorf("deck %v, want Study on top of Prayer", CardNames(g.MyCards.Deck))
+	}
+}
+
+func TestScriptPlay(t *testing.T) {
+	w := newTestWorld(t)
+	setCards(w.games[0], nil, []*Card{Study, Festival}, nil)
+	works := w.games[0].Stats.Works
+	g := runTestScript(w, scriptCard(t, "look 1", "choose revealed type Work as x", "play x", "faith 5"))
+	if g.script != nil {
+		t.Error("playing a card should end the script")
+	}
+	for _, o := range w.games {
+		if !slices.Equal(o.InPlayWork, []*Card{Festival}) || o.Stats.Works != works+Festival.works || o.Stats.Faith != Festival.faith {
+			t.Errorf("%s: in play %v, works %d, faith %d", o.Name, CardNames(o.InPlayWork), o.Stats.Works, o.Stats.Faith)
+		}
+	}
+}
+
+func TestScriptStats(t *testing.T) {
+	w := newTestWorld(t)
+	ts := w.games[0].Stats
+	runTestScript(w, scriptCard(t, "works 1", "blessings 2", "faith 3"))
+	for _, o := range w.games {
+		if want := (TurnStats{ts.Works + 1, ts.Blessings + 2, ts.Faith + 3}); o.Stats != want {
+			t.Errorf("%s: %+v, want %+v", o.Name, o.Stats, want)
+		}
+	}
+}
+
+func TestScriptAffectOthersAndWait(t *testing.T) {
+	w := newTestWorld(t)
+	g, other := w.games[0], w.games[1]
+	setCards(other, nil, []*Card{Study}, nil)
+	g.runScript(scriptCard(t, "affect others", "wait", "faith 1", "others:", "draw 1"), SectionPlay)
+	if g.script == nil || !g.script.waiting || g.OtherDecisions != 1 {
+		t.Fatal("should wait for the other player")
+	}
+	w.sync()
+	if !slices.Equal(other.MyCards.Hand, []*Card{Study}) {
+		t.Errorf("p2's hand %v", CardNames(other.MyCards.Hand))
+	}
+	if g.script != nil || g.OtherDecisions != 0 || g.Stats.Faith != 1 {
+		t.Errorf("should carry on once p2 is done: script %v, waiting for %d, faith %d", g.script, g.OtherDecisions, g.Stats.Faith)
+	}
+	if log := g.ActionLog; log[len(log)-2] != "p2 drew 1 card" {
+		t.Errorf("log %q", log[len(log)-2])
+	}
+}
+
+func TestScriptDistribute(t *testing.T) {
+	w := newTestWorld(t)
+	other := w.games[1]
+	setCards(other, nil, nil, nil)
+	runTestScript(w, scriptCard(t, "distribute Temptation"))
+	if !slices.Equal(other.MyCards.Discard, []*Card{Temptation}) {
+		t.Errorf("p2's discard %v", CardNames(other.MyCards.Discard))
+	}
+}
+
+func TestScriptControlFlow(t *testing.T) {
+	for _, tc := range []struct {
+		lines []string
+		faith int
+	}{
+		{[]string{"if hand > 2", "faith 1", "else", "faith 2", "end"}, 1},
+		{[]string{"if not hand > 2", "faith 1", "else", "faith 2", "end"}, 2},
+		{[]string{"if hand = 3", "faith 4", "end"}, 4},
+		{[]string{"repeat 3", "faith 1", "end"}, 3},
+		{[]string{"repeat 0", "faith 1", "end"}, 0},
+		{[]string{"repeat 2", "repeat 2", "faith 1", "end", "end"}, 4},
+		{[]string{"while hand < 5", "draw 1", "faith 1", "end"}, 2},
+		{[]string{"faith 1", "stop", "faith 1"}, 1},
+		{[]string{"faith empty+2"}, 2},
+		{[]string{"faith hand-1"}, 2},
+	} {
+		w := newTestWorld(t)
+		setCards(w.games[0], []*Card{Study, Study, Study}, []*Card{Study, Study, Study}, nil)
+		g := runTestScript(w, scriptCard(t, tc.lines...))
+		if g.Stats.Faith != tc.faith {
+			t.Errorf("%q: faith %d, want %d", tc.lines, g.Stats.Faith, tc.faith)
+		}
+	}
+}
+
+func TestScriptStepLimit(t *testing.T) {
+	w := newTestWorld(t)
+	g := runTestScript(w, scriptCard(t, "while 1 > 0", "end"))
+	if g.script != nil {
+		t.Error("a script that never ends should be stopped")
+	}
+	// the count carries on across waits, so waiting in the loop doesn't reset it
+	g.runScript(scriptCard(t, "while 1 > 0", "affect others", "wait", "end"), SectionPlay)
+	w.sync()
+	if g.script != nil {
+		t.Error("a script that waits in a loop should be stopped")
+	}
+}
+
+func TestLostCoin(t *testing.T) {
+	w := newTestWorld(t)
+	setCards(w.games[0], nil, []*Card{Study}, []*Card{Study, Devotion})
+	g := w.games[0]
+	g.runScript(LostCoin, SectionPlay)
+	if msg, _ := g.PromptDecision(); msg != "You may select a card from your discard to put on top of your deck" {
+		t.Errorf("prompt %q", msg)
+	}
+	g.decide(Devotion)
+	w.sync()
+	if !slices.Equal(g.MyCards.Deck, []*Card{Study, Devotion}) || !slices.Equal(g.MyCards.Discard, []*Card{Study}) {
+		t.Errorf("deck %v, discard %v", CardNames(g.MyCards.Deck), CardNames(g.MyCards.Discard))
+	}
+}
+
+func TestPlan(t *testing.T) {
+	w := newTestWorld(t)
+	setCards(w.games[0], nil, []*Card{Parable, Study, Prayer, Devotion}, nil)
+	g := w.games[0]
+	g.runScript(Plan, SectionPlay)
+	// keep both, putting Prayer back on top of Devotion
+	g.skipDecision()
+	g.skipDecision()
+	if msg, skippable := g.PromptDecision(); msg != "Select a card you revealed to put on top of your deck" || skippable {
+		t.Errorf("prompt %q, %v", msg, skippable)
+	}
+	g.decide(Prayer)
+	w.sync()
+	if !slices.Equal(g.MyCards.Deck, []*Card{Parable, Study, Devotion, Prayer}) || g.script != nil {
+		t.Errorf("deck %v", CardNames(g.MyCards.Deck))
+	}
+	// release one and discard the other
+	g.runScript(Plan, SectionPlay)
+	g.decide(Prayer)
+	g.skipDecision()
+	g.decide(Devotion)
+	w.sync()
+	if !slices.Equal(g.MyCards.Deck, []*Card{Parable, Study}) || !slices.Equal(g.MyCards.Discard, []*Card{Devotion}) {
+		t.Errorf("deck %v, discard %v", CardNames(g.MyCards.Deck), CardNames(g.MyCards.Discard))
+	}
+	if !slices.Equal(g.Kingdom.Released, []*Card{Prayer}) {
+		t.Errorf("released %v", CardNames(g.Kingdom.Released))
+	}
+}
+
+func TestInspiration(t *testing.T) {
+	w := newTestWorld(t)
+	setCards(w.games[0], nil, []*Card{Festival}, nil)
+	g := w.games[0]
+	g.runScript(Inspiration, SectionPlay)
+	g.decide(Festival)
+	w.sync()
+	if !slices.Equal(g.InPlayWork, []*Card{Festival}) || len(g.MyCards.Discard) != 0 {
+		t.Errorf("in play %v, discard %v", CardNames(g.InPlayWork), CardNames(g.MyCards.Discard))
+	}
+	// anything else is discarded
+	setCards(g, nil, []*Card{Prayer}, nil)
+	runTestScript(w, Inspiration)
+	if !slices.Equal(g.MyCards.Discard, []*Card{Prayer}) || g.Decision != -1 {
+		t.Errorf("discard %v", CardNames(g.MyCards.Discard))
+	}
+}
+
+func TestCollection(t *testing.T) {
+	w := newTestWorld(t)
+	// draws from the top: Festival (set aside), Craft (kept), then Studies until 7 in hand
+	setCards(w.games[0], []*Card{Study, Study, Study}, []*Card{Prayer, Study, Study, Study, Craft, Festival}, nil)
+	g := w.games[0]
+	g.runScript(Collection, SectionPlay)
+	g.decide(Festival)
+	g.skipDecision()
+	w.sync()
+	if len(g.MyCards.Hand) != 7 || count(g.MyCards.Hand, Craft) != 1 || count(g.MyCards.Hand, Festival) != 0 {
+		t.Errorf("hand %v", CardNames(g.MyCards.Hand))
+	}
+	if !slices.Equal(g.MyCards.Discard, []*Card{Festival}) || !slices.Equal(g.MyCards.Deck, []*Card{Prayer}) {
+		t.Errorf("discard %v, deck %v", CardNames(g.MyCards.Discard), CardNames(g.MyCards.Deck))
+	}
+}
+
+func TestNewCreation(t *testing.T) {
+	w := newTestWorld(t)
+	setCards(w.games[0], []*Card{Study, Study, Prayer}, []*Card{Festival, Craft}, nil)
+	g := w.games[0]
+	g.runScript(NewCreation, SectionPlay)
+	g.decide(Study)
+	g.decide(Study)
+	g.skipDecision()
+	w.sync()
+	if len(g.MyCards.Hand) != 3 || count(g.MyCards.Hand, Prayer) != 1 || count(g.MyCards.Hand, Craft) != 1 || count(g.MyCards.Hand, Festival) != 1 {
+		t.Errorf("hand %v", CardNames(g.MyCards.Hand))
+	}
+	if !slices.Equal(g.MyCards.Discard, []*Card{Study, Study}) || len(g.MyCards.Deck) != 0 {
+		t.Errorf("discard %v, deck %v", CardNames(g.MyCards.Discard), CardNames(g.MyCards.Deck))
+	}
+	// discarding nothing draws nothing
+	setCards(g, []*Card{Prayer}, []*Card{Study}, nil)
+	g.runScript(NewCreation, SectionPlay)
+	g.skipDecision()
+	w.sync()
+	if !slices.Equal(g.MyCards.Hand, []*Card{Prayer}) || g.script != nil {
+		t.Errorf("hand %v", CardNames(g.MyCards.Hand))
+	}
+}
+
+func TestDuplication(t *testing.T) {
+	w := newTestWorld(t)
+	g := w.games[0]
+	setCards(g, []*Card{Duplication, Festival}, []*Card{Study}, nil)
+	if !g.MakeMove(Action{Kind: ActionPlayWork, Card: Duplication}) {
+		t.Fatal("can't play Duplication")
+	}
+	g.decide(Festival)
+	w.sync()
+	// Festival is played twice without using up works, and is in play once
+	for _, g := range w.games {
+		if !slices.Equal(g.InPlayWork, []*Card{Duplication, Festival}) || g.Stats.Works != 4 || g.Stats.Faith != 4 || g.Stats.Blessings != 3 {
+			t.Errorf("%s: in play %v, stats %+v", g.Name, CardNames(g.InPlayWork), g.Stats)
+		}
+	}
+}
+
+func TestDuplicationWaitsForTheFirstPlay(t *testing.T) {
+	w := newTestWorld(t)
+	g := w.games[0]
+	setCards(g, []*Card{Duplication, Wisdom, Study, Study}, []*Card{Prayer}, nil)
+	g.MakeMove(Action{Kind: ActionPlayWork, Card: Duplication})
+	g.decide(Wisdom)
+	// the second Wisdom only starts once the first has released its Study
+	if g.script == nil || g.script.c != Wisdom || len(g.replays) != 1 {
+		t.Fatal("Wisdom should be choosing, with one play left")
+	}
+	g.decide(Study)
+	g.decide(Study)
+	w.sync()
+	if !slices.Equal(g.Kingdom.Released, []*Card{Study, Study}) || g.Stats.Faith != 6 || len(g.replays) != 0 {
+		t.Errorf("released %v, faith %d", CardNames(g.Kingdom.Released), g.Stats.Faith)
+	}
+	if !slices.Equal(g.InPlayWork, []*Card{Duplication, Wisdom}) {
+		t.Errorf("in play %v", CardNames(g.InPlayWork))
+	}
+}
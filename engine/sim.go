@@ -0,0 +1,278 @@
+// plays bot games without a window, to see how strong cards and strategies are
+package engine
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// games still going after this many rounds are stopped and left out of the stats
+const MaxSimRounds = 100
+
+// the z score for 95% confidence intervals
+const SimZ = 1.96
+
+// how one simulated game went
+type SimGame struct {
+	// the players' results by seat in the strategy list, or nil if the game was stopped
+	players []PlayerResult
+	// how many rounds the game lasted
+	rounds int
+}
+
+// what a sim plays
+type SimConfig struct {
+	kingdom    []*Card
+	rules      *Ruleset
+	strategies []Strategy
+}
+
+// kingdom-of-heaven sim and kingdom-sim: play many games between strategies and report how each did
+func RunSim(args []string) {
+	fs := flag.NewFlagSet("sim", flag.ExitOnError)
+	packDir := fs.String("packs", "packs", "directory of card packs to load")
+	games := fs.Int("games", 1000, "how many games to play")
+	seed := fs.Int64("seed", 1, "seed for the shuffles, the same seed plays the same games")
+	kingdomFlag := fs.String("kingdom", KingdomPresets[0].Name, "a kingdom preset, or verses separated by commas")
+	rulesFlag := fs.String("rules", Rulesets[0].Name, "the ruleset to play by")
+	strategiesFlag := fs.String("strategies", "bigmoney,bigmoney", "2 to 6 strategies separated by commas, one per player: "+strings.Join(StrategyNames, ", "))
+	workers := fs.Int("workers", runtime.NumCPU(), "how many games to play at once")
+	fs.Parse(args)
+	LoadPacks(*packDir)
+
+	sc, err := newSimConfig(*kingdomFlag, *rulesFlag, *strategiesFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	results := sc.run(*games, *seed, *workers)
+	fmt.Print(sc.report(results, *seed))
+}
+
+// warns that a strategy searching for a set time can play differently with the same seed
+func warnIfTimed(s Strategy) {
+	if m, ok := s.(*MCTS); ok && m.budget > 0 {
+		log.Printf("warning: %s searches for a set time, so the same seed may not play the same games", s)
+	}
+}
+
+// reads the sim flags into what to play
+func newSimConfig(kingdom, rules, strategies string) (*SimConfig, error) {
+	sc := &SimConfig{rules: RulesetNamed(rules)}
+	if sc.rules.Name != rules {
+		return nil, fmt.Errorf("unknown ruleset %s", rules)
+	}
+	var err error
+	if sc.kingdom, err = kingdomNamed(kingdom); err != nil {
+		return nil, err
+	}
+	for _, spec := range strings.Split(strategies, ",") {
+		s, err := strategyNamed(strings.TrimSpace(spec))
+		if err != nil {
+			return nil, err
+		}
+		// a verse strategy can only buy verses that are in the kingdom
+		if vm, ok := s.(VerseMoney); ok {
+			for _, c := range vm.verses {
+				if !slices.ContainsFunc(sc.kingdom, func(k *Card) bool { return k.pileName() == c.pileName() }) {
+					return nil, fmt.Errorf("strategy %s buys %s, which is not in the kingdom", s, c.Name)
+				}
+			}
+		}
+		warnIfTimed(s)
+		sc.strategies = append(sc.strategies, s)
+	}
+	if len(sc.strategies) < 2 || len(sc.strategies) > 6 {
+		return nil, errors.New("a sim needs 2 to 6 strategies")
+	}
+	return sc, nil
+}
+
+// returns the kingdom preset with the name, or the verses in a list separated by commas
+func kingdomNamed(s string) ([]*Card, error) {
+	if i := slices.IndexFunc(KingdomPresets, func(p KingdomPreset) bool { return p.Name == s }); i != -1 {
+		return KingdomPresets[i].Kingdom, nil
+	}
+	var kingdom []*Card
+	for _, name := range strings.Split(s, ",") {
+		c, ok := CardNameMap[strings.TrimSpace(name)]
+		if !ok || c.basic || c.nonSupply {
+			return nil, fmt.Errorf("%q is not a verse or kingdom preset", name)
+		}
+		kingdom = append(kingdom, c)
+	}
+	return kingdom, nil
+}
+
+// plays the games, workers at a time. each game gets its own seed from the sim's seed, so the
+// results are the same however many workers there are
+func (sc *SimConfig) run(games int, seed int64, workers int) []SimGame {
+	rng := rand.New(rand.NewSource(seed))
+	seeds := make([]int64, games)
+	for i := range seeds {
+		seeds[i] = rng.Int63()
+	}
+	results := make([]SimGame, games)
+	runParallel(games, workers, func(i int) {
+		// the first seat moves round so every strategy goes first as often
+		results[i] = sc.play(seeds[i], i%len(sc.strategies))
+	})
+	return results
+}
+
+// calls play with 0 to n-1, workers at a time
+func runParallel(n, workers int, play func(i int)) {
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				play(i)
+			}
+		}()
+	}
+	for i := range n {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
+
+// plays one game between bots in a local room, one message at a time so the same seed always
+// plays the same game. first is the seat in the strategy list that goes first
+func (sc *SimConfig) play(seed int64, first int) SimGame {
+	w := sc.start(seed, first)
+	bots, conns, n := w.games, w.conns, len(w.games)
+	for stepped := true; stepped; {
+		stepped = false
+		for i, g := range bots {
+			if g.botStep(conns[i]) {
+				stepped = true
+			}
+		}
+		if bots[0].Turn >= MaxSimRounds*n {
+			return SimGame{rounds: MaxSimRounds}
+		}
+	}
+	result := bots[0].Result
+	if result == nil {
+		return SimGame{}
+	}
+	sg := SimGame{players: make([]PlayerResult, n)}
+	for _, p := range result.Players {
+		i, _ := strconv.Atoi(p.Name[1:])
+		sg.players[i-1] = p
+		sg.rounds = max(sg.rounds, p.Turns)
+	}
+	return sg
+}
+
+// sets up a game in a local room and starts it, with the kingdom and seed. the game for a strategy
+// that is nil is left for the caller to play
+func (sc *SimConfig) start(seed int64, first int) *World {
+	room := NewLocalRoom()
+	n := len(sc.strategies)
+	w := &World{games: make([]*Game, n), conns: make([]*LocalClient, n)}
+	for i, s := range sc.strategies {
+		g := NewGame()
+		g.State = Lobby
+		g.Name = "p" + strconv.Itoa(i+1)
+		g.Room = "sim"
+		g.strategy = s
+		w.conns[i] = room.Join(g.Name, (i-first+n)%n)
+		g.Conn = w.conns[i]
+		w.games[i] = g
+	}
+	// the first player sets the rules and the kingdom once everyone knows who is playing
+	options := DefaultRoomOptions()
+	options.Rules = sc.rules.Name
+	w.conns[first].Send(append([]string{SetOptions}, options.Encode()...))
+	for i, g := range w.games {
+		g.botStep(w.conns[i])
+		g.TurnModulus = g.PlayerOrder()
+		g.State = Picking
+	}
+	w.conns[first].Send([]string{SetSeed, strconv.FormatInt(seed, 10)})
+	w.conns[first].Send(append([]string{SetKingdom}, CardNames(sc.kingdom)...))
+	return w
+}
+
+// describes how each strategy did, with 95% confidence intervals
+func (sc *SimConfig) report(results []SimGame, seed int64) string {
+	var names []string
+	for _, c := range sc.kingdom {
+		names = append(names, c.Name)
+	}
+	_, finished := where(results, func(sg SimGame) bool { return sg.players != nil })
+	msg := "Kingdom: " + strings.Join(names, ", ") + "\n"
+	msg += "Rules: " + sc.rules.Name + "\n"
+	msg += fmt.Sprintf("Games: %d with seed %d, %d stopped after %d rounds\n\n", len(results), seed, len(results)-len(finished), MaxSimRounds)
+	if len(finished) == 0 {
+		return msg
+	}
+	var rounds []float64
+	for _, sg := range finished {
+		rounds = append(rounds, float64(sg.rounds))
+	}
+	mean, ci := meanCI(rounds)
+	msg += fmt.Sprintf("Game length: %.1f ± %.1f rounds\n\n", mean, ci)
+	msg += fmt.Sprintf("%-4s %-24s %-24s %-16s %s\n", "Seat", "Strategy", "Win rate", "Glory", "Turns")
+	for i, s := range sc.strategies {
+		// a shared win counts as part of a win
+		var wins float64
+		var glory, turns []float64
+		for _, sg := range finished {
+			p := sg.players[i]
+			if p.Place == 1 {
+				_, winners := where(sg.players, func(q PlayerResult) bool { return q.Place == 1 })
+				wins += 1 / float64(len(winners))
+			}
+			glory = append(glory, float64(p.Glory))
+			turns = append(turns, float64(p.Turns))
+		}
+		rate := wins / float64(len(finished))
+		low, high := wilson(wins, len(finished))
+		gm, gci := meanCI(glory)
+		tm, tci := meanCI(turns)
+		msg += fmt.Sprintf("%-4d %-24s %-24s %-16s %s\n", i+1, s,
+			fmt.Sprintf("%.1f%% (%.1f-%.1f%%)", 100*rate, 100*low, 100*high),
+			fmt.Sprintf("%.1f ± %.1f", gm, gci),
+			fmt.Sprintf("%.1f ± %.1f", tm, tci))
+	}
+	return msg
+}
+
+// returns the mean and the half width of its confidence interval
+func meanCI(xs []float64) (float64, float64) {
+	var sum float64
+	for _, x := range xs {
+		sum += x
+	}
+	mean := sum / float64(len(xs))
+	if len(xs) < 2 {
+		return mean, 0
+	}
+	var sq float64
+	for _, x := range xs {
+		sq += (x - mean) * (x - mean)
+	}
+	return mean, SimZ * math.Sqrt(sq/float64(len(xs)-1)/float64(len(xs)))
+}
+
+// returns the Wilson score interval for a win rate of wins out of n
+func wilson(wins float64, n int) (float64, float64) {
+	p, nf, z2 := wins/float64(n), float64(n), SimZ*SimZ
+	center := (p + z2/(2*nf)) / (1 + z2/nf)
+	half := SimZ * math.Sqrt(p*(1-p)/nf+z2/(4*nf*nf)) / (1 + z2/nf)
+	return center - half, center + half
+}
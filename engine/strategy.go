@@ -0,0 +1,360 @@
+// strategies that decide what bots do
+package engine
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// things a bot can do on its turn
+const (
+	// play a work card from hand
+	ActionPlayWork = iota
+	// play a faith card from hand, or every faith card in hand if there is no card
+	ActionPlayFaith
+	// buy the top card of a pile
+	ActionBuy
+	// end the current phase
+	ActionEndPhase
+	// pick a card for the current decision
+	ActionChoose
+	// skip the current decision
+	ActionSkip
+)
+
+type Action struct {
+	Kind int
+	// the card to play, buy or pick
+	Card *Card
+}
+
+// e.g. "buy Miracle", for logs and the env's action names, so no two actions share one
+func (a Action) String() string {
+	switch a.Kind {
+	case ActionPlayWork:
+		return "play " + a.Card.Name
+	case ActionPlayFaith:
+		if a.Card != nil {
+			return "play faith " + a.Card.Name
+		}
+		return "play all faith"
+	case ActionBuy:
+		return "buy " + a.Card.Name
+	case ActionEndPhase:
+		return "end phase"
+	case ActionChoose:
+		return "choose " + a.Card.Name
+	}
+	return "skip"
+}
+
+// decides what a bot does. strategies only see the game through a View
+type Strategy interface {
+	// the next thing to do, when it is our turn and nothing needs deciding
+	act(v View) Action
+	// picks one of the choices for the current decision, or nil to skip it if it can be skipped
+	decide(v View, choices []*Card, skippable bool) *Card
+	String() string
+}
+
+// what a strategy can see: the bot's cards, the kingdom and the turn. it hands out copies, so
+// strategies can't change the game
+type View struct {
+	g *Game
+}
+
+func (v View) hand() []*Card {
+	return slices.Clone(v.g.MyCards.Hand)
+}
+
+// every card we own, wherever it is
+func (v View) owned() []*Card {
+	pc := v.g.MyCards
+	owned := slices.Concat(pc.Hand, pc.Deck, pc.Discard, pc.decision, pc.aside)
+	// cards in play are the current player's
+	if v.g.TurnModulus[v.g.Turn%len(v.g.Players)] == v.g.Name {
+		owned = slices.Concat(owned, v.g.InPlayWork, v.g.InPlayFaith)
+	}
+	return owned
+}
+
+// how many copies of the card we own
+func (v View) count(name string) int {
+	_, cards := where(v.owned(), func(c *Card) bool { return c.Name == name })
+	return len(cards)
+}
+
+func (v View) stats() TurnStats {
+	return v.g.Stats
+}
+
+func (v View) phase() string {
+	return v.g.Phase
+}
+
+// how many cards are left in the pile with the card on top, or 0 if there is none
+func (v View) left(name string) int {
+	p := v.g.Kingdom.PileWithTop(name)
+	if p == nil {
+		return 0
+	}
+	return len(p.cards)
+}
+
+// returns true if the card is on top of a supply pile and we can afford it
+func (v View) canBuy(name string) bool {
+	p := v.g.Kingdom.PileWithTop(name)
+	return p != nil && v.g.canBuy(p)
+}
+
+// the cards on top of the supply piles
+func (v View) supply() []*Card {
+	var tops []*Card
+	for _, p := range v.g.Kingdom.Piles {
+		if c := p.Top(); p.supply && c != nil {
+			tops = append(tops, c)
+		}
+	}
+	return tops
+}
+
+func (v View) emptyPiles() int {
+	return v.g.Kingdom.emptyPiles()
+}
+
+// a source for random choices: the bot's stream of the game's seed, so the same seed plays the same game
+func (v View) random() *rand.Rand {
+	return v.g.RNG
+}
+
+// every legal move we can make right now
+func (v View) moves() []Action {
+	return v.g.LegalMoves()
+}
+
+// where the choices for the decision we are making come from, and what the pick is for, e.g. play
+func (v View) decision() (int, string) {
+	return v.g.DecisionSource(), v.g.script.instr().purpose
+}
+
+// the strategies bots can use, by name. verse strategies are named verse:Card or verse:Card+Card,
+// search strategies mcts:ITERATIONS or mcts:DURATION, and personalities by their name in lower case
+// with dashes for spaces, e.g. trial-happy
+var StrategyNames = []string{"bigmoney", "random", "verse:CARD[+CARD]", "mcts[:ITERATIONS|DURATION]", "PERSONALITY"}
+
+// returns the strategy described by spec, e.g. "bigmoney" or "verse:Bezalel+Craft"
+func strategyNamed(spec string) (Strategy, error) {
+	name, arg, _ := strings.Cut(spec, ":")
+	switch name {
+	case "bigmoney":
+		return BigMoney{}, nil
+	case "random":
+		return Random{}, nil
+	case "verse":
+		var verses []*Card
+		for _, n := range strings.Split(arg, "+") {
+			c, ok := CardNameMap[n]
+			if !ok || c.basic || c.nonSupply {
+				return nil, fmt.Errorf("strategy %s: %q is not a verse", spec, n)
+			}
+			verses = append(verses, c)
+		}
+		if len(verses) > 2 {
+			return nil, fmt.Errorf("strategy %s: at most two verses", spec)
+		}
+		return VerseMoney{verses}, nil
+	case "mcts":
+		// no time limit by default, so the same seed plays the same games
+		s := &MCTS{iterations: MCTSIterations, rollout: BigMoney{}}
+		if arg == "" {
+			return s, nil
+		}
+		if d, err := time.ParseDuration(arg); err == nil && d > 0 {
+			s.iterations, s.budget = math.MaxInt, d
+			return s, nil
+		}
+		if n, err := strconv.Atoi(arg); err == nil && n > 0 {
+			s.iterations, s.budget = n, 0
+			return s, nil
+		}
+		return nil, fmt.Errorf("strategy %s: %q is not a number of iterations or a duration", spec, arg)
+	}
+	for _, p := range Personalities {
+		if p.String() == spec {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown strategy %s, try one of %s", spec, strings.Join(StrategyNames, ", "))
+}
+
+// only buys faith and glory: Miracle with 8 faith, Devotion with 6 and Prayer with 3
+type BigMoney struct{}
+
+func (BigMoney) act(v View) Action {
+	return playThenBuy(v, bigMoneyBuy)
+}
+
+func (BigMoney) decide(v View, choices []*Card, skippable bool) *Card {
+	return defaultDecide(v, choices, skippable)
+}
+
+func (BigMoney) String() string {
+	return "bigmoney"
+}
+
+func bigMoneyBuy(v View) *Card {
+	for _, c := range []*Card{Miracle, Devotion, Prayer} {
+		if v.canBuy(c.Name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// how many copies of each of its verses a VerseMoney bot buys
+const VerseCopies = 2
+
+// plays Big Money, but buys a couple of copies of one or two verses when it can't afford Miracle
+type VerseMoney struct {
+	verses []*Card
+}
+
+func (s VerseMoney) act(v View) Action {
+	return playThenBuy(v, func(v View) *Card {
+		if v.canBuy(Miracle.Name) {
+			return Miracle
+		}
+		for _, c := range s.verses {
+			if v.count(c.Name) < VerseCopies && v.canBuy(c.Name) {
+				return c
+			}
+		}
+		return bigMoneyBuy(v)
+	})
+}
+
+func (VerseMoney) decide(v View, choices []*Card, skippable bool) *Card {
+	return defaultDecide(v, choices, skippable)
+}
+
+func (s VerseMoney) String() string {
+	var names []string
+	for _, c := range s.verses {
+		names = append(names, c.Name)
+	}
+	return "verse:" + strings.Join(names, "+")
+}
+
+// plays a random move whenever it can, for beginners
+type Random struct{}
+
+func (Random) act(v View) Action {
+	moves := actions(v)
+	return moves[v.random().Intn(len(moves))]
+}
+
+func (Random) decide(v View, choices []*Card, skippable bool) *Card {
+	moves := decisionMoves(choices, skippable)
+	return moves[v.random().Intn(len(moves))].Card
+}
+
+func (Random) String() string {
+	return "random"
+}
+
+// plays Big Money, but buys verses with the tags it likes when it can't afford Miracle, the most
+// expensive first
+type Personality struct {
+	name string
+	// what roles it likes verses to play, and how many copies of each it buys
+	tags   []string
+	copies int
+}
+
+// the personalities bots can have
+var Personalities = []*Personality{
+	{"Big Money", nil, 0},
+	{"Trial-happy", []string{"attack"}, 3},
+	{"Engine builder", []string{"village", "draw", "cantrip"}, 3},
+	{"Collector", []string{"gainer", "trasher"}, 2},
+}
+
+// returns the personality with the given name, or the first if there is none
+func personalityNamed(name string) *Personality {
+	for _, p := range Personalities {
+		if p.name == name {
+			return p
+		}
+	}
+	return Personalities[0]
+}
+
+func (p *Personality) act(v View) Action {
+	return playThenBuy(v, func(v View) *Card {
+		if v.canBuy(Miracle.Name) {
+			return Miracle
+		}
+		var best *Card
+		for _, c := range v.supply() {
+			if v.canBuy(c.Name) && v.count(c.Name) < p.copies && slices.ContainsFunc(c.tags, func(t string) bool { return slices.Contains(p.tags, t) }) && (best == nil || c.Cost > best.Cost) {
+				best = c
+			}
+		}
+		if best != nil {
+			return best
+		}
+		return bigMoneyBuy(v)
+	})
+}
+
+func (p *Personality) decide(v View, choices []*Card, skippable bool) *Card {
+	return defaultDecide(v, choices, skippable)
+}
+
+// the name in lower case with dashes, e.g. trial-happy
+func (p *Personality) String() string {
+	return strings.ReplaceAll(strings.ToLower(p.name), " ", "-")
+}
+
+// plays works, villages first, then every faith card and buys what buy picks, ending the phase
+// when there is nothing left to do
+func playThenBuy(v View, buy func(v View) *Card) Action {
+	switch v.phase() {
+	case WorkPhase:
+		_, works := where(v.hand(), func(c *Card) bool { return slices.Contains(c.CardTypes, WorkType) })
+		if len(works) > 0 && v.stats().Works > 0 {
+			return Action{Kind: ActionPlayWork, Card: slices.MaxFunc(works, func(a, b *Card) int { return cmp.Compare(a.works, b.works) })}
+		}
+	case BlessingPhase:
+		if slices.ContainsFunc(v.hand(), func(c *Card) bool { return slices.Contains(c.CardTypes, FaithType) }) {
+			return Action{Kind: ActionPlayFaith}
+		}
+		if c := buy(v); c != nil {
+			return Action{Kind: ActionBuy, Card: c}
+		}
+	}
+	return Action{Kind: ActionEndPhase}
+}
+
+// decides the way most strategies would: picks the most useful card to play or put on the deck,
+// gains the most expensive card, skips what can be skipped and otherwise gives up the least useful card
+func defaultDecide(v View, choices []*Card, skippable bool) *Card {
+	if len(choices) == 0 {
+		return nil
+	}
+	byCost := func(a, b *Card) int { return cmp.Compare(a.Cost+a.glory, b.Cost+b.glory) }
+	from, purpose := v.decision()
+	switch {
+	case purpose == "play", purpose == "topdeck", from == FromKingdom:
+		return slices.MaxFunc(choices, byCost)
+	case skippable:
+		return nil
+	}
+	return slices.MinFunc(choices, byCost)
+}
@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestStrategyNamed(t *testing.T) {
+	for _, spec := range []string{"bigmoney", "random", "verse:Craft", "verse:Craft+Festival", "trial-happy"} {
+		s, err := strategyNamed(spec)
+		if err != nil || s.String() != spec {
+			t.Errorf("%s is %v, %v", spec, s, err)
+		}
+	}
+	for spec, want := range map[string]*MCTS{
+		"mcts":      {iterations: MCTSIterations},
+		"mcts:50":   {iterations: 50},
+		"mcts:20ms": {iterations: math.MaxInt, budget: 20 * time.Millisecond},
+	} {
+		s, err := strategyNamed(spec)
+		if m, ok := s.(*MCTS); err != nil || !ok || m.iterations != want.iterations || m.budget != want.budget {
+			t.Errorf("%s is %v, %v", spec, s, err)
+		}
+	}
+	for spec, want := range map[string]string{
+		"greedy":                    "unknown strategy greedy, try one of bigmoney, random, verse:CARD[+CARD], mcts[:ITERATIONS|DURATION], PERSONALITY",
+		"verse:Prayer":              `strategy verse:Prayer: "Prayer" is not a verse`,
+		"verse:Nothing":             `strategy verse:Nothing: "Nothing" is not a verse`,
+		"verse:Craft+Festival+Plan": "strategy verse:Craft+Festival+Plan: at most two verses",
+		"mcts:0":                    `strategy mcts:0: "0" is not a number of iterations or a duration`,
+		"mcts:soon":                 `strategy mcts:soon: "soon" is not a number of iterations or a duration`,
+	} {
+		if _, err := strategyNamed(spec); err == nil || err.Error() != want {
+			t.Errorf("%s gave %v, want %s", spec, err, want)
+		}
+	}
+}
+
+// in the blessing phase with nothing left to play and the faith given
+func buyingWith(w *World, faith int) View {
+	g := w.games[0]
+	setCards(g, nil, g.MyCards.Deck, g.MyCards.Discard)
+	g.Phase, g.Stats = BlessingPhase, TurnStats{Blessings: 1, Faith: faith}
+	return View{g}
+}
+
+func TestBigMoneyBuys(t *testing.T) {
+	w := newTestWorld(t)
+	if a := (BigMoney{}).act(View{w.games[0]}); a.Kind != ActionEndPhase {
+		t.Errorf("with no works to play BigMoney should end the work phase, not %v", a)
+	}
+	for faith, want := range map[int]Action{
+		9: {Kind: ActionBuy, Card: Miracle},
+		7: {Kind: ActionBuy, Card: Devotion},
+		3: {Kind: ActionBuy, Card: Prayer},
+		2: {Kind: ActionEndPhase},
+	} {
+		if a := (BigMoney{}).act(buyingWith(w, faith)); a != want {
+			t.Errorf("with %d faith BigMoney did %v, want %v", faith, a, want)
+		}
+	}
+	v := buyingWith(w, 0)
+	v.g.MyCards.Hand = []*Card{Prayer}
+	if a := (BigMoney{}).act(v); a != (Action{Kind: ActionPlayFaith}) {
+		t.Errorf("BigMoney should play all its faith before buying, not %v", a)
+	}
+}
+
+func TestVerseMoneyBuysCopies(t *testing.T) {
+	w := newTestWorld(t)
+	s := VerseMoney{[]*Card{Craft}}
+	v := buyingWith(w, Craft.Cost)
+	for i := range VerseCopies {
+		if a := s.act(v); a != (Action{Kind: ActionBuy, Card: Craft}) {
+			t.Fatalf("with %d Craft VerseMoney did %v", i, a)
+		}
+		v.g.MyCards.Discard = append(v.g.MyCards.Discard, Craft)
+	}
+	if a := s.act(v); a.Card == Craft {
+		t.Errorf("VerseMoney bought more than %d Craft", VerseCopies)
+	}
+	if a := s.act(buyingWith(w, Miracle.Cost)); a.Card != Miracle {
+		t.Errorf("VerseMoney should buy Miracle when it can, not %v", a)
+	}
+}
+
+// a strategy changing what its view hands it doesn't change the game
+func TestViewHandsOutCopies(t *testing.T) {
+	w := newTestWorld(t)
+	g := w.games[0]
+	v := View{g}
+	v.hand()[0] = Miracle
+	v.owned()[0] = Miracle
+	if v.count(Miracle.Name) != 0 || g.MyCards.Hand[0] == Miracle {
+		t.Error("changing the view's cards changed ours")
+	}
+	if v.count(Study.Name) != 7 || v.count(Parable.Name) != 3 {
+		t.Errorf("we own %d Study and %d Parable", v.count(Study.Name), v.count(Parable.Name))
+	}
+}
@@ -0,0 +1,347 @@
+// plays round robin tournaments between strategies on random kingdoms, and rates them
+package engine
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// everyone's rating before their first game
+	EloStart = 1500
+	// how far one game moves a rating, split between the other players at the table
+	EloK = 24
+)
+
+// the strategies in a tournament unless others are given
+var TournamentStrategies = []string{"bigmoney", "random", "trial-happy", "engine-builder", "collector"}
+
+// one game of a tournament
+type TournamentGame struct {
+	Kingdom []string           `json:"kingdom"`
+	Seed    int64              `json:"seed"`
+	Rounds  int                `json:"rounds"`
+	Players []TournamentPlayer `json:"players"`
+}
+
+// how a strategy did in one game. seats are numbered from 1 in turn order
+type TournamentPlayer struct {
+	Strategy string `json:"strategy"`
+	Seat     int    `json:"seat"`
+	Glory    int    `json:"glory"`
+	Turns    int    `json:"turns"`
+	Place    int    `json:"place"`
+	Shared   bool   `json:"shared"`
+}
+
+type Rating struct {
+	Strategy string  `json:"strategy"`
+	Elo      float64 `json:"elo"`
+	Games    int     `json:"games"`
+	// shared wins count as part of a win
+	Wins float64 `json:"wins"`
+}
+
+// how a strategy's win rate changes with a card in the kingdom
+type CardDelta struct {
+	Strategy string `json:"strategy"`
+	Card     string `json:"card"`
+	// games with and without the card, and the win rate in each
+	With           int     `json:"with"`
+	Without        int     `json:"without"`
+	WinRateWith    float64 `json:"winRateWith"`
+	WinRateWithout float64 `json:"winRateWithout"`
+	Delta          float64 `json:"delta"`
+}
+
+type TournamentResult struct {
+	Games   []TournamentGame `json:"games"`
+	Ratings []Rating         `json:"ratings"`
+	Cards   []CardDelta      `json:"cards"`
+}
+
+// kingdom-of-heaven tournament: plays every table of strategies for each player count, with each
+// strategy going first in turn, and writes the games, ratings and card deltas
+func RunTournament(args []string) {
+	fs := flag.NewFlagSet("tournament", flag.ExitOnError)
+	packDir := fs.String("packs", "packs", "directory of card packs to load")
+	strategiesFlag := fs.String("strategies", strings.Join(TournamentStrategies, ","), "strategies separated by commas: "+strings.Join(StrategyNames, ", "))
+	playersFlag := fs.String("players", "", "player counts, e.g. 2-4 or 3, from 2 to as many as there are strategies if left out")
+	rounds := fs.Int("rounds", 10, "how many random kingdoms each table plays, once with each strategy going first")
+	seed := fs.Int64("seed", 1, "seed for the kingdoms and shuffles")
+	rulesFlag := fs.String("rules", Rulesets[0].Name, "the ruleset to play by")
+	format := fs.String("format", "csv", "csv or json")
+	out := fs.String("out", "tournament", "where to write: out.json, or out-games.csv, out-ratings.csv and out-cards.csv")
+	workers := fs.Int("workers", runtime.NumCPU(), "how many games to play at once")
+	fs.Parse(args)
+	LoadPacks(*packDir)
+
+	var strategies []Strategy
+	for _, spec := range strings.Split(*strategiesFlag, ",") {
+		s, err := strategyNamed(strings.TrimSpace(spec))
+		if err != nil {
+			log.Fatal(err)
+		}
+		if _, ok := s.(VerseMoney); ok {
+			log.Fatal("verse strategies need their verses in the kingdom, try them with sim")
+		}
+		warnIfTimed(s)
+		// the ratings are by name, so two strategies can't share one
+		if slices.ContainsFunc(strategies, func(o Strategy) bool { return o.String() == s.String() }) {
+			log.Fatalf("strategy %s is in the tournament twice", s)
+		}
+		strategies = append(strategies, s)
+	}
+	if *playersFlag == "" {
+		*playersFlag = fmt.Sprintf("2-%d", min(max(len(strategies), 2), 6))
+	}
+	low, high, err := playerCounts(*playersFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// each strategy sits at a table at most once, so there is no table bigger than the strategies
+	if len(strategies) < high {
+		log.Fatalf("%d player tables need at least %d strategies", high, high)
+	}
+	rules := RulesetNamed(*rulesFlag)
+	if rules.Name != *rulesFlag {
+		log.Fatalf("unknown ruleset %s", *rulesFlag)
+	}
+	if *format != "csv" && *format != "json" {
+		log.Fatalf("unknown format %s", *format)
+	}
+
+	tr := playTournament(strategies, low, high, *rounds, *seed, rules, *workers)
+	if *format == "json" {
+		err = tr.writeJSON(*out + ".json")
+	} else {
+		err = tr.writeCSV(*out)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, r := range tr.Ratings {
+		fmt.Printf("%-24s %6.0f  %5.1f%% of %d games\n", r.Strategy, r.Elo, 100*r.Wins/float64(r.Games), r.Games)
+	}
+}
+
+// reads player counts like 2-6 or 4
+func playerCounts(s string) (int, int, error) {
+	lowS, highS, isRange := strings.Cut(s, "-")
+	low, err := strconv.Atoi(lowS)
+	high := low
+	if err == nil && isRange {
+		high, err = strconv.Atoi(highS)
+	}
+	if err != nil || low < 2 || high > 6 || low > high {
+		return 0, 0, errors.New("player counts must be from 2 to 6, like 2-6 or 4")
+	}
+	return low, high, nil
+}
+
+// plays each table of strategies, from low to high players, on rounds random kingdoms, rotating the
+// seats so each strategy goes first once on each, and works out the ratings and card deltas
+func playTournament(strategies []Strategy, low, high, rounds int, seed int64, rules *Ruleset, workers int) *TournamentResult {
+	rng := rand.New(rand.NewSource(seed))
+	var configs []*SimConfig
+	var firsts []int
+	var seeds []int64
+	for n := low; n <= high; n++ {
+		for _, table := range combinations(len(strategies), n) {
+			for range rounds {
+				sc := &SimConfig{kingdom: PickKingdom(DefaultRoomOptions(), nil, rng), rules: rules}
+				for _, i := range table {
+					sc.strategies = append(sc.strategies, strategies[i])
+				}
+				for first := range n {
+					configs = append(configs, sc)
+					firsts = append(firsts, first)
+					seeds = append(seeds, rng.Int63())
+				}
+			}
+		}
+	}
+	results := make([]SimGame, len(configs))
+	runParallel(len(configs), workers, func(i int) {
+		results[i] = configs[i].play(seeds[i], firsts[i])
+	})
+
+	tr := &TournamentResult{}
+	for i, sg := range results {
+		if sg.players == nil {
+			continue
+		}
+		sc := configs[i]
+		tg := TournamentGame{Seed: seeds[i], Rounds: sg.rounds}
+		for _, c := range sc.kingdom {
+			tg.Kingdom = append(tg.Kingdom, c.Name)
+		}
+		n := len(sc.strategies)
+		for j, p := range sg.players {
+			// the strategy at j sat (j-first) places after the first player
+			seat := (j-firsts[i]+n)%n + 1
+			tg.Players = append(tg.Players, TournamentPlayer{sc.strategies[j].String(), seat, p.Glory, p.Turns, p.Place, p.Shared})
+		}
+		slices.SortFunc(tg.Players, func(a, b TournamentPlayer) int { return a.Seat - b.Seat })
+		tr.Games = append(tr.Games, tg)
+	}
+	tr.rate(strategies)
+	tr.cardDeltas(strategies)
+	return tr
+}
+
+// returns every way to pick k of 0 to n-1, in order
+func combinations(n, k int) [][]int {
+	if k == 0 {
+		return [][]int{nil}
+	}
+	var combos [][]int
+	for i := k - 1; i < n; i++ {
+		for _, c := range combinations(i, k-1) {
+			combos = append(combos, append(c, i))
+		}
+	}
+	return combos
+}
+
+// the strategy's share of the win in a game, or -1 if it didn't play
+func winShare(tg TournamentGame, strategy string) float64 {
+	i := slices.IndexFunc(tg.Players, func(p TournamentPlayer) bool { return p.Strategy == strategy })
+	if i == -1 {
+		return -1
+	}
+	if tg.Players[i].Place != 1 {
+		return 0
+	}
+	_, winners := where(tg.Players, func(p TournamentPlayer) bool { return p.Place == 1 })
+	return 1 / float64(len(winners))
+}
+
+// works out Elo ratings game by game, counting each game as a match between every pair at the table
+func (tr *TournamentResult) rate(strategies []Strategy) {
+	elo := make(map[string]float64)
+	for _, s := range strategies {
+		elo[s.String()] = EloStart
+		tr.Ratings = append(tr.Ratings, Rating{Strategy: s.String(), Elo: EloStart})
+	}
+	for _, tg := range tr.Games {
+		change := make(map[string]float64)
+		for _, a := range tg.Players {
+			for _, b := range tg.Players {
+				if a.Strategy == b.Strategy {
+					continue
+				}
+				score := 0.5
+				if a.Place < b.Place {
+					score = 1
+				} else if a.Place > b.Place {
+					score = 0
+				}
+				expected := 1 / (1 + math.Pow(10, (elo[b.Strategy]-elo[a.Strategy])/400))
+				change[a.Strategy] += EloK / float64(len(tg.Players)-1) * (score - expected)
+			}
+		}
+		for name, c := range change {
+			elo[name] += c
+		}
+		for i := range tr.Ratings {
+			if share := winShare(tg, tr.Ratings[i].Strategy); share != -1 {
+				tr.Ratings[i].Games++
+				tr.Ratings[i].Wins += share
+			}
+		}
+	}
+	for i := range tr.Ratings {
+		tr.Ratings[i].Elo = elo[tr.Ratings[i].Strategy]
+	}
+	slices.SortStableFunc(tr.Ratings, func(a, b Rating) int { return cmp.Compare(b.Elo, a.Elo) })
+}
+
+// works out, for each strategy and verse, the strategy's win rate with and without the verse in the
+// kingdom
+func (tr *TournamentResult) cardDeltas(strategies []Strategy) {
+	for _, s := range strategies {
+		for _, c := range NonBaseCards {
+			d := CardDelta{Strategy: s.String(), Card: c.Name}
+			var winsWith, winsWithout float64
+			for _, tg := range tr.Games {
+				share := winShare(tg, d.Strategy)
+				if share == -1 {
+					continue
+				}
+				if slices.Contains(tg.Kingdom, c.Name) {
+					d.With++
+					winsWith += share
+				} else {
+					d.Without++
+					winsWithout += share
+				}
+			}
+			if d.With == 0 || d.Without == 0 {
+				continue
+			}
+			d.WinRateWith = winsWith / float64(d.With)
+			d.WinRateWithout = winsWithout / float64(d.Without)
+			d.Delta = d.WinRateWith - d.WinRateWithout
+			tr.Cards = append(tr.Cards, d)
+		}
+	}
+}
+
+func (tr *TournamentResult) writeJSON(path string) error {
+	data, err := json.MarshalIndent(tr, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// writes the games, ratings and card deltas to out-games.csv, out-ratings.csv and out-cards.csv
+func (tr *TournamentResult) writeCSV(out string) error {
+	games := [][]string{{"game", "seed", "rounds", "kingdom", "strategy", "seat", "glory", "turns", "place", "shared"}}
+	for i, tg := range tr.Games {
+		for _, p := range tg.Players {
+			games = append(games, []string{strconv.Itoa(i + 1), strconv.FormatInt(tg.Seed, 10), strconv.Itoa(tg.Rounds), strings.Join(tg.Kingdom, " "),
+				p.Strategy, strconv.Itoa(p.Seat), strconv.Itoa(p.Glory), strconv.Itoa(p.Turns), strconv.Itoa(p.Place), strconv.FormatBool(p.Shared)})
+		}
+	}
+	ratings := [][]string{{"strategy", "elo", "games", "wins"}}
+	for _, r := range tr.Ratings {
+		ratings = append(ratings, []string{r.Strategy, strconv.FormatFloat(r.Elo, 'f', 1, 64), strconv.Itoa(r.Games), strconv.FormatFloat(r.Wins, 'f', 2, 64)})
+	}
+	cards := [][]string{{"strategy", "card", "with", "without", "winRateWith", "winRateWithout", "delta"}}
+	for _, d := range tr.Cards {
+		cards = append(cards, []string{d.Strategy, d.Card, strconv.Itoa(d.With), strconv.Itoa(d.Without),
+			strconv.FormatFloat(d.WinRateWith, 'f', 4, 64), strconv.FormatFloat(d.WinRateWithout, 'f', 4, 64), strconv.FormatFloat(d.Delta, 'f', 4, 64)})
+	}
+	for name, rows := range map[string][][]string{"games": games, "ratings": ratings, "cards": cards} {
+		if err := writeCSVFile(out+"-"+name+".csv", rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCSVFile(path string, rows [][]string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := csv.NewWriter(f).WriteAll(rows); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestPlayerCounts(t *testing.T) {
+	for s, want := range map[string][2]int{"2-6": {2, 6}, "4": {4, 4}, "3-5": {3, 5}} {
+		if low, high, err := playerCounts(s); err != nil || low != want[0] || high != want[1] {
+			t.Errorf("%s is %d-%d, %v", s, low, high, err)
+		}
+	}
+	for _, s := range []string{"", "1", "7", "2-7", "5-3", "two", "2-", "2-6-8"} {
+		if _, _, err := playerCounts(s); err == nil {
+			t.Errorf("%q should be an error", s)
+		}
+	}
+}
+
+func TestCombinations(t *testing.T) {
+	if got := combinations(4, 2); !slices.EqualFunc(got, [][]int{{0, 1}, {0, 2}, {1, 2}, {0, 3}, {1, 3}, {2, 3}}, slices.Equal) {
+		t.Errorf("2 of 4 are %v", got)
+	}
+	if got := combinations(3, 3); len(got) != 1 || !slices.Equal(got[0], []int{0, 1, 2}) {
+		t.Errorf("3 of 3 are %v", got)
+	}
+	if got := combinations(2, 3); len(got) != 0 {
+		t.Errorf("3 of 2 are %v", got)
+	}
+}
+
+// a two player game between a and b where a came first, or they shared the win
+func tournamentGame(a, b string, shared bool, kingdom ...string) TournamentGame {
+	place := 2
+	if shared {
+		place = 1
+	}
+	return TournamentGame{Kingdom: kingdom, Players: []TournamentPlayer{
+		{Strategy: a, Seat: 1, Place: 1, Shared: shared},
+		{Strategy: b, Seat: 2, Place: place, Shared: shared},
+	}}
+}
+
+func TestRate(t *testing.T) {
+	strategies := []Strategy{Random{}, BigMoney{}, VerseMoney{[]*Card{Craft}}}
+	tr := &TournamentResult{Games: []TournamentGame{
+		tournamentGame("bigmoney", "random", false),
+		tournamentGame("bigmoney", "random", false),
+		tournamentGame("bigmoney", "verse:Craft", true),
+	}}
+	if share := winShare(tr.Games[2], "verse:Craft"); share != 0.5 {
+		t.Errorf("a shared win is worth %v", share)
+	}
+	if share := winShare(tr.Games[0], "verse:Craft"); share != -1 {
+		t.Errorf("a game we didn't play is worth %v", share)
+	}
+	tr.rate(strategies)
+	want := []Rating{{Strategy: "bigmoney", Games: 3, Wins: 2.5}, {Strategy: "verse:Craft", Games: 1, Wins: 0.5}, {Strategy: "random", Games: 2}}
+	total := 0.0
+	for i, r := range tr.Ratings {
+		total += r.Elo
+		if r.Strategy != want[i].Strategy || r.Games != want[i].Games || r.Wins != want[i].Wins {
+			t.Errorf("rating %d is %+v, want %+v", i, r, want[i])
+		}
+	}
+	if math.Abs(total-3*EloStart) > 1e-9 {
+		t.Errorf("ratings add up to %v, not %v", total, 3*EloStart)
+	}
+}
+
+func TestCardDeltas(t *testing.T) {
+	tr := &TournamentResult{Games: []TournamentGame{
+		tournamentGame("bigmoney", "random", false, Craft.Name),
+		tournamentGame("random", "bigmoney", false, Craft.Name),
+		tournamentGame("bigmoney", "random", false, Festival.Name),
+	}}
+	tr.cardDeltas([]Strategy{BigMoney{}})
+	i := slices.IndexFunc(tr.Cards, func(d CardDelta) bool { return d.Card == Craft.Name })
+	if want := (CardDelta{"bigmoney", Craft.Name, 2, 1, 0.5, 1, -0.5}); i == -1 || tr.Cards[i] != want {
+		t.Errorf("Craft's delta is %+v, want %+v", tr.Cards, want)
+	}
+	// a card in every game or none has nothing to compare
+	if slices.ContainsFunc(tr.Cards, func(d CardDelta) bool { return d.Card == Merchant.Name }) {
+		t.Error("Merchant was in no games but has a delta")
+	}
+}
+
+// each strategy at a table goes first once on the same kingdom
+func TestPlayTournamentRotatesSeats(t *testing.T) {
+	tr := playTournament([]Strategy{BigMoney{}, Random{}}, 2, 2, 1, 1, Rulesets[0], 1)
+	if len(tr.Games) != 2 {
+		t.Fatalf("%d games, want 2", len(tr.Games))
+	}
+	for i, first := range []string{"bigmoney", "random"} {
+		if p := tr.Games[i].Players[0]; p.Seat != 1 || p.Strategy != first {
+			t.Errorf("game %d starts with %+v, want %s", i, p, first)
+		}
+	}
+	if !slices.Equal(tr.Games[0].Kingdom, tr.Games[1].Kingdom) {
+		t.Errorf("the games were on %v and %v", tr.Games[0].Kingdom, tr.Games[1].Kingdom)
+	}
+	if len(tr.Ratings) != 2 || tr.Ratings[0].Games != 2 {
+		t.Errorf("ratings are %+v", tr.Ratings)
+	}
+}
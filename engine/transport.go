@@ -0,0 +1,132 @@
+// how messages get between the players in a room
+package engine
+
+import (
+	"strconv"
+	"sync"
+)
+
+// sends and receives the messages of a room. every player receives every message, their own too,
+// in the same order
+type Transport interface {
+	Send(message []string)
+	// blocks until the next message arrives
+	Receive() []string
+	// how many of our own messages we haven't received back yet
+	Pending() int
+	Close()
+}
+
+// a room where everyone is in this process, e.g. a player and their bots
+type LocalRoom struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	// every message sent so far, and who sent it
+	log     [][]string
+	senders []*LocalClient
+}
+
+func NewLocalRoom() *LocalRoom {
+	r := &LocalRoom{}
+	r.cond = sync.NewCond(&r.mu)
+	return r
+}
+
+// a player's connection to a local room
+type LocalClient struct {
+	room *LocalRoom
+	// the index of the next message to receive
+	next int
+	// how many messages we have sent, and how many of those we have received back
+	sent, received int
+}
+
+// joins the room, reading it from the start like a pulsar subscription, and tells everyone
+func (r *LocalRoom) Join(playerName string, pid int) *LocalClient {
+	c := r.connect()
+	c.Send(JoinMessage(playerName, pid))
+	return c
+}
+
+// connects to the room without telling anyone, for players who are already in the game
+func (r *LocalRoom) connect() *LocalClient {
+	return &LocalClient{room: r}
+}
+
+func (c *LocalClient) Send(message []string) {
+	c.room.mu.Lock()
+	defer c.room.mu.Unlock()
+	c.room.log = append(c.room.log, message)
+	c.room.senders = append(c.room.senders, c)
+	c.sent++
+	c.room.cond.Broadcast()
+}
+
+func (c *LocalClient) Receive() []string {
+	c.room.mu.Lock()
+	defer c.room.mu.Unlock()
+	for c.next == len(c.room.log) {
+		c.room.cond.Wait()
+	}
+	message := c.room.log[c.next]
+	if c.room.senders[c.next] == c {
+		c.received++
+	}
+	c.next++
+	return message
+}
+
+func (c *LocalClient) Pending() int {
+	c.room.mu.Lock()
+	defer c.room.mu.Unlock()
+	return c.sent - c.received
+}
+
+// returns true if there are no messages waiting to be received
+func (c *LocalClient) caughtUp() bool {
+	c.room.mu.Lock()
+	defer c.room.mu.Unlock()
+	return c.next == len(c.room.log)
+}
+
+func (c *LocalClient) Close() {}
+
+// message types
+const (
+	JoinedLobby   = "J"
+	LeftLobby     = "L"
+	ToggledReady  = "TR"
+	HasPacks      = "H"
+	SetOptions    = "O"
+	SetKingdom    = "SK"
+	PickedKingdom = "PK"
+	EndPhase      = "E"
+	Played        = "P"
+	PlayedFaith   = "PF"
+	Bought        = "B"
+	Gained        = "G"
+	Released      = "R"
+	Discarded     = "D"
+	Glory         = "Gl"
+	CardSpecific  = "C"
+	AffectOthers  = "A"
+	Affected      = "Ad"
+	Distributed   = "Ds"
+	Revealed      = "Rv"
+	AddedStats    = "S"
+	SetBot        = "SB"
+	SetSeed       = "SS"
+	Shuffled      = "Sh"
+)
+
+// mark Played messages for cards that don't use up a work: played by another card, and played
+// again by a card that is already in play
+const (
+	FreePlay    = "F"
+	PlayedAgain = "PA"
+)
+
+// the message a player sends when they join a room, with the number that decides turn order
+func JoinMessage(playerName string, pid int) []string {
+	return []string{JoinedLobby, playerName, strconv.Itoa(pid)}
+}
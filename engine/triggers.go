@@ -0,0 +1,69 @@
+// triggered abilities that wait for something to happen in the game
+package engine
+
+import "slices"
+
+// events that triggered abilities can wait for
+const (
+	EventCardPlayed = iota
+	EventCardGained
+)
+
+// something that happened in the game
+type Event struct {
+	kind int
+	// who it happened to
+	player string
+	// the card played or gained
+	c *Card
+}
+
+// a card's ability waiting for an event
+type Trigger struct {
+	// the card the ability is from
+	c *Card
+	// who the ability belongs to
+	player string
+	// what kind of event it is waiting for
+	kind int
+	// whether it goes away at the end of the turn
+	thisTurn bool
+}
+
+// sets up the triggered abilities a card has when it is played. every client does this so they all
+// fire the same abilities in the same order
+func (g *Game) registerTriggers(c *Card, player string) {
+	switch c.Name {
+	case Belief.Name:
+		// the first time they play a Prayer this turn
+		g.triggers = append(g.triggers, &Trigger{c: c, player: player, kind: EventCardPlayed, thisTurn: true})
+	}
+}
+
+// tells all triggered abilities about an event, removing those that are done
+func (g *Game) emit(e Event) {
+	// fire a copy since abilities can set up more abilities
+	for _, t := range slices.Clone(g.triggers) {
+		if t.kind == e.kind && g.fire(t, e) {
+			g.triggers = slices.DeleteFunc(g.triggers, func(o *Trigger) bool { return o == t })
+		}
+	}
+}
+
+// removes the abilities that only last this turn, once it ends
+func (g *Game) endTriggers() {
+	g.triggers = slices.DeleteFunc(g.triggers, func(t *Trigger) bool { return t.thisTurn })
+}
+
+// runs a triggered ability on an event of the kind it waits for, returning true if it is done
+func (g *Game) fire(t *Trigger, e Event) bool {
+	switch t.c.Name {
+	case Belief.Name:
+		if e.player == t.player && e.c == Prayer {
+			g.Stats.Faith++
+			g.ActionLog = append(g.ActionLog, t.player+" got +1 Faith from "+t.c.Name)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package engine
+
+import "testing"
+
+// makes the move for p1 and lets everyone see it
+func playMove(t *testing.T, w *World, a Action) {
+	t.Helper()
+	if !w.games[0].MakeMove(a) {
+		t.Fatalf("%v isn't legal", a)
+	}
+	w.sync()
+}
+
+func TestBeliefFiresOnFirstPrayer(t *testing.T) {
+	w := newTestWorld(t)
+	setCards(w.games[0], []*Card{Belief, Study, Prayer, Prayer}, []*Card{Study}, nil)
+	playMove(t, w, Action{Kind: ActionPlayWork, Card: Belief})
+	playMove(t, w, Action{Kind: ActionEndPhase})
+	// a Study doesn't count, the first Prayer does and the second doesn't
+	for _, tc := range []struct {
+		c     *Card
+		faith int
+	}{{Study, 1}, {Study, 2}, {Prayer, 5}, {Prayer, 7}} {
+		playMove(t, w, Action{Kind: ActionPlayFaith, Card: tc.c})
+		for _, g := range w.games {
+			if g.Stats.Faith != tc.faith {
+				t.Fatalf("%s: faith after %s = %d, want %d", g.Name, tc.c.Name, g.Stats.Faith, tc.faith)
+			}
+		}
+	}
+}
+
+func TestBeliefLastsOneTurn(t *testing.T) {
+	w := newTestWorld(t)
+	setCards(w.games[0], []*Card{Belief}, []*Card{Study}, nil)
+	playMove(t, w, Action{Kind: ActionPlayWork, Card: Belief})
+	for _, g := range w.games {
+		if len(g.triggers) != 1 {
+			t.Fatalf("%s has %d triggers after Belief, want 1", g.Name, len(g.triggers))
+		}
+	}
+	playMove(t, w, Action{Kind: ActionEndPhase})
+	playMove(t, w, Action{Kind: ActionEndPhase})
+	for _, g := range w.games {
+		if len(g.triggers) != 0 || g.Turn != 1 {
+			t.Errorf("%s has %d triggers on turn %d, want none on turn 1", g.Name, len(g.triggers), g.Turn)
+		}
+	}
+}
+
+func TestOtherPlayersPrayersDontFireBelief(t *testing.T) {
+	w := newTestWorld(t)
+	g := w.games[1]
+	g.registerTriggers(Belief, w.games[0].Name)
+	g.emit(Event{kind: EventCardPlayed, player: g.Name, c: Prayer})
+	if g.Stats.Faith != 0 || len(g.triggers) != 1 {
+		t.Errorf("faith %d with %d triggers, want 0 with Belief still waiting", g.Stats.Faith, len(g.triggers))
+	}
+}
@@ -0,0 +1,14 @@
+package engine
+
+// returns the indices and values of the elements of s that satisfy f
+func where[T any](s []T, f func(T) bool) ([]int, []T) {
+	var is []int
+	var ts []T
+	for i, t := range s {
+		if f(t) {
+			is = append(is, i)
+			ts = append(ts, t)
+		}
+	}
+	return is, ts
+}
@@ -0,0 +1,67 @@
+// shows the advisor's suggestion on the table
+package game
+
+import (
+	"image/color"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/zehongharryqu/kingdom-of-heaven/engine"
+)
+
+// the colour the suggested card or button is outlined in
+var AdvisorColor = color.RGBA{255, 215, 0, 255}
+
+// outlines the suggested card or button and writes the reason above the cards in play
+func (g *Game) drawAdvice(screen *ebiten.Image) {
+	if !g.advisor {
+		return
+	}
+	a, reason, ok := g.Advice()
+	if !ok {
+		return
+	}
+	outline := func(x, y, w, h int) {
+		vector.StrokeRect(screen, float32(x), float32(y), float32(w), float32(h), 3, AdvisorColor, true)
+	}
+	switch {
+	case a.Kind == engine.ActionPlayWork, a.Kind == engine.ActionPlayFaith && a.Card != nil:
+		if i := slices.Index(g.MyCards.Hand, a.Card); i != -1 {
+			outline((ScreenWidth-ArtSmallWidth*len(g.MyCards.Hand))/2+i*ArtSmallWidth, ScreenHeight-ArtSmallWidth, ArtSmallWidth, ArtSmallWidth)
+		}
+	case a.Kind == engine.ActionPlayFaith:
+		outline(PlayAllX, EndPhaseY, EndPhaseWidth, EndPhaseHeight)
+	case a.Kind == engine.ActionBuy:
+		if p := g.Kingdom.PileWithTop(a.Card.Name); p != nil {
+			x, y := slotPosition(p.Slot)
+			outline(x, y, ArtSmallWidth, ArtSmallWidth)
+		}
+	case a.Kind == engine.ActionEndPhase, a.Kind == engine.ActionSkip:
+		outline(EndPhaseX, EndPhaseY, EndPhaseWidth, EndPhaseHeight)
+	case a.Kind == engine.ActionChoose:
+		switch g.DecisionSource() {
+		case engine.FromHand:
+			if i := slices.Index(g.MyCards.Hand, a.Card); i != -1 {
+				outline((ScreenWidth-ArtSmallWidth*len(g.MyCards.Hand))/2+i*ArtSmallWidth, ScreenHeight-ArtSmallWidth, ArtSmallWidth, ArtSmallWidth)
+			}
+		case engine.FromRevealed, engine.FromDiscard:
+			if row := g.DecisionRow(); slices.Contains(row, a.Card) {
+				outline((ScreenWidth-ArtSmallWidth*len(row))/2+slices.Index(row, a.Card)*ArtSmallWidth, DecisionY, ArtSmallWidth, ArtSmallWidth)
+			}
+		case engine.FromKingdom:
+			if p := g.Kingdom.PileWithTop(a.Card.Name); p != nil {
+				x, y := slotPosition(p.Slot)
+				outline(x, y, ArtSmallWidth, ArtSmallWidth)
+			}
+		}
+	}
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(0, InPlayY-10-NormalFontSize)
+	op.ColorScale.ScaleWithColor(AdvisorColor)
+	text.Draw(screen, "Hint: "+reason, &text.GoTextFace{
+		Source: MPlusFaceSource,
+		Size:   SmallFontSize,
+	}, op)
+}
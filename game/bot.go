@@ -0,0 +1,40 @@
+// lets the host set up the bots in the lobby
+package game
+
+import (
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/zehongharryqu/kingdom-of-heaven/engine"
+)
+
+// lets the host pick each bot's difficulty and personality: up and down pick the bot, left and right
+// change its difficulty and P its personality
+func (g *Game) updateBots() {
+	bots := g.BotsInOrder()
+	if len(bots) == 0 {
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyDown) {
+		g.botSeat = (g.botSeat + 1) % len(bots)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) {
+		g.botSeat = (g.botSeat + len(bots) - 1) % len(bots)
+	}
+	g.botSeat = min(g.botSeat, len(bots)-1)
+	pd := g.Players[bots[g.botSeat]]
+	bs := *pd.Bot
+	n := len(engine.DifficultyNames)
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyRight):
+		bs.Difficulty = (bs.Difficulty + 1) % n
+	case inpututil.IsKeyJustPressed(ebiten.KeyLeft):
+		bs.Difficulty = (bs.Difficulty + n - 1) % n
+	case inpututil.IsKeyJustPressed(ebiten.KeyP):
+		bs.Personality = engine.Personalities[(slices.Index(engine.Personalities, bs.Personality)+1)%len(engine.Personalities)]
+	default:
+		return
+	}
+	g.SetBot(pd.Name, bs)
+}
@@ -0,0 +1,143 @@
+// draws cards that don't have art from their rules text, and the rules text of hovered cards
+package game
+
+import (
+	"image/color"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/zehongharryqu/kingdom-of-heaven/engine"
+)
+
+// frame sizes
+const (
+	FrameBorderBig   = 20
+	FrameBorderSmall = 3
+	FrameNameSize    = 32
+	FrameSmallSize   = 10
+	RulesTextPadding = 4
+)
+
+// the box the rules text is shown in under a hovered card
+var RulesTextColor = color.RGBA{0, 0, 0, 220}
+
+// the cards' art as drawn, made the first time each is needed
+var bigArts, smallArts = map[*engine.Card]*ebiten.Image{}, map[*engine.Card]*ebiten.Image{}
+
+// the detailed art, rendered from the rules text if the card has none
+func bigArt(c *engine.Card) *ebiten.Image {
+	if img, ok := bigArts[c]; ok {
+		return img
+	}
+	var img *ebiten.Image
+	if c.ArtBig != nil {
+		img = ebiten.NewImageFromImage(c.ArtBig)
+	} else {
+		img = renderBigFrame(c)
+	}
+	bigArts[c] = img
+	return img
+}
+
+// the art for piles and hands, rendered from the rules text if the card has none
+func smallArt(c *engine.Card) *ebiten.Image {
+	if img, ok := smallArts[c]; ok {
+		return img
+	}
+	var img *ebiten.Image
+	if c.ArtSmall != nil {
+		img = ebiten.NewImageFromImage(c.ArtSmall)
+	} else {
+		img = renderSmallFrame(c)
+	}
+	smallArts[c] = img
+	return img
+}
+
+// draws the hovered card's big art at x, with its name, types, cost and rules text in a box under it,
+// so every card can be read, not only those rendered from their text
+func drawCardPreview(dst *ebiten.Image, c *engine.Card, x int) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(x), 0)
+	dst.DrawImage(bigArt(c), op)
+	face := &text.GoTextFace{Source: MPlusFaceSource, Size: SmallFontSize}
+	width := float64(ArtBigWidth - 2*RulesTextPadding)
+	msg := wrapText(c.Name+" - "+c.TypeLine()+" - costs "+strconv.Itoa(c.Cost), face, width)
+	if c.Text != "" {
+		msg += "\n" + wrapText(c.Text, face, width)
+	}
+	lineSpacing := SmallFontSize * 1.4
+	height := int(float64(strings.Count(msg, "\n")+1)*lineSpacing) + 2*RulesTextPadding
+	// under the art if it fits, otherwise as low as it goes
+	y := min(ArtBigHeight, ScreenHeight-height)
+	vector.DrawFilledRect(dst, float32(x), float32(y), ArtBigWidth, float32(height), RulesTextColor, true)
+	textOp := &text.DrawOptions{}
+	textOp.GeoM.Translate(float64(x+RulesTextPadding), float64(y+RulesTextPadding))
+	textOp.LineSpacing = lineSpacing
+	textOp.ColorScale.ScaleWithColor(color.White)
+	text.Draw(dst, msg, face, textOp)
+}
+
+// the border colour, matching the drawn cards
+func frameColor(c *engine.Card) color.Color {
+	switch {
+	case slices.Contains(c.CardTypes, engine.TemptationType):
+		return color.RGBA{255, 49, 49, 255}
+	case slices.Contains(c.CardTypes, engine.GloryType):
+		return color.RGBA{0, 191, 99, 255}
+	case slices.Contains(c.CardTypes, engine.FaithType):
+		return color.RGBA{255, 222, 89, 255}
+	}
+	return color.RGBA{115, 115, 115, 255}
+}
+
+// draws the name, types, rules text and cost on a blank card
+func renderBigFrame(c *engine.Card) *ebiten.Image {
+	img := ebiten.NewImage(ArtBigWidth, ArtBigHeight)
+	img.Fill(frameColor(c))
+	vector.DrawFilledRect(img, FrameBorderBig, FrameBorderBig, ArtBigWidth-2*FrameBorderBig, ArtBigHeight-2*FrameBorderBig, color.White, true)
+	drawFrameText(img, c.Name, FrameNameSize, ArtBigWidth/2, FrameBorderBig+FrameNameSize, text.AlignCenter)
+	drawFrameText(img, c.TypeLine(), SmallFontSize, ArtBigWidth/2, FrameBorderBig+FrameNameSize*2, text.AlignCenter)
+	face := &text.GoTextFace{Source: MPlusFaceSource, Size: NormalFontSize}
+	drawFrameText(img, wrapText(c.Text, face, ArtBigWidth-4*FrameBorderBig), NormalFontSize, ArtBigWidth/2, ArtBigHeight/2, text.AlignCenter)
+	drawFrameText(img, strconv.Itoa(c.Cost), NormalFontSize, ArtBigWidth-FrameBorderBig-NormalFontSize/2, ArtBigHeight-FrameBorderBig-NormalFontSize/2, text.AlignEnd)
+	return img
+}
+
+// draws the name and cost on a small blank card
+func renderSmallFrame(c *engine.Card) *ebiten.Image {
+	img := ebiten.NewImage(ArtSmallWidth, ArtSmallWidth)
+	img.Fill(frameColor(c))
+	vector.DrawFilledRect(img, FrameBorderSmall, FrameBorderSmall, ArtSmallWidth-2*FrameBorderSmall, ArtSmallWidth-2*FrameBorderSmall, color.White, true)
+	// shrink the name until it fits
+	size := float64(FrameSmallSize)
+	for size > 6 && text.Advance(c.Name, &text.GoTextFace{Source: MPlusFaceSource, Size: size}) > ArtSmallWidth-2*FrameBorderSmall {
+		size--
+	}
+	drawFrameText(img, c.Name, size, ArtSmallWidth/2, ArtSmallWidth/3, text.AlignCenter)
+	drawFrameText(img, strconv.Itoa(c.Cost), FrameSmallSize, ArtSmallWidth-FrameBorderSmall-2, ArtSmallWidth-FrameBorderSmall-FrameSmallSize/2, text.AlignEnd)
+	return img
+}
+
+// draws black text centred vertically on y and aligned on x
+func drawFrameText(dst *ebiten.Image, msg string, size float64, x, y int, align text.Align) {
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(float64(x), float64(y))
+	op.PrimaryAlign = align
+	op.SecondaryAlign = text.AlignCenter
+	op.LineSpacing = size * 1.4
+	op.ColorScale.ScaleWithColor(color.Black)
+	text.Draw(dst, msg, &text.GoTextFace{
+		Source: MPlusFaceSource,
+		Size:   size,
+	}, op)
+}
+
+// breaks text into lines no wider than width in face
+func wrapText(s string, face text.Face, width float64) string {
+	return engine.WrapText(s, width, func(line string) float64 { return text.Advance(line, face) })
+}
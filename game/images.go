@@ -0,0 +1,24 @@
+// the images the table is drawn with, besides the cards
+package game
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/zehongharryqu/kingdom-of-heaven/assets"
+)
+
+var (
+	DiscardSmall = loadImage("discard.png")
+	DeckSmall    = loadImage("deck.png")
+	ReleaseSmall = loadImage("release.png")
+
+	EndWorkPhase     = loadImage("endworkphase.png")
+	EndBlessingPhase = loadImage("endblessingphase.png")
+)
+
+func loadImage(name string) *ebiten.Image {
+	img, err := assets.LoadArt(name)
+	if err != nil {
+		panic(err)
+	}
+	return ebiten.NewImageFromImage(img)
+}
@@ -0,0 +1,499 @@
+// the window: the lobby, the picker and the table, drawn from the game and driven by the mouse and keyboard
+package game
+
+import (
+	"bytes"
+	"flag"
+	"image/color"
+	"log"
+	"maps"
+	"math/rand"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/examples/resources/fonts"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/zehongharryqu/kingdom-of-heaven/engine"
+)
+
+// sizes
+const (
+	ScreenHeight = 480
+	ScreenWidth  = 640
+
+	ArtBigHeight  = 400
+	ArtBigWidth   = 300
+	ArtSmallWidth = 50
+
+	BigFontSize    = 20
+	NormalFontSize = 16
+	SmallFontSize  = 12
+
+	InPlayY   = 250
+	DecisionY = 310
+
+	EndPhaseX      = 245
+	EndPhaseY      = 370
+	EndPhaseWidth  = 150
+	EndPhaseHeight = 50
+
+	PlayAllX = 85
+)
+
+var (
+	MPlusFaceSource *text.GoTextFaceSource
+)
+
+func init() {
+	s, err := text.NewGoTextFaceSource(bytes.NewReader(fonts.MPlus1pRegular_ttf))
+	if err != nil {
+		log.Fatal(err)
+	}
+	MPlusFaceSource = s
+}
+
+// a player's game, with what only their window needs
+type Game struct {
+	*engine.Game
+	// for typing in the lobby
+	t Typewriter
+	// which bot the host is setting up in the lobby
+	botSeat int
+	// whether the advisor suggests what to do, which only we see
+	advisor bool
+}
+
+func (g *Game) Update() error {
+	switch g.State {
+	case engine.RoomName:
+		g.t.Update()
+		if g.t.confirmedName != "" && g.t.confirmedRoom != "" {
+			g.Room, g.Name = g.t.confirmedRoom, g.t.confirmedName
+			if g.t.bots > 0 {
+				// a room of our own, where we go first and pick the kingdom
+				room := engine.NewLocalRoom()
+				g.Conn = room.Join(g.Name, 0)
+				for i, name := range engine.BotNamesFor(g.Name, g.t.bots) {
+					engine.StartBot(room, name, i+1, engine.DefaultBotSettings)
+				}
+			} else {
+				g.Conn = newPulsarClient(g.Room, g.Name, g.RNG.Intn(10))
+			}
+			// tell the others which packs we have so everyone plays with the same cards
+			g.Conn.Send(append([]string{engine.HasPacks, g.Name}, engine.PackChecksums()...))
+			g.State = engine.Lobby
+			go g.ReceiveMessages()
+		}
+	case engine.Lobby:
+		if repeatingKeyPressed(ebiten.KeyEnter) || repeatingKeyPressed(ebiten.KeyNumpadEnter) {
+			g.Conn.Send([]string{engine.ToggledReady, g.Name})
+		}
+		// the host picks the room options, and sets up the bots
+		if len(g.Players) > 0 && g.PlayerOrder()[0] == g.Name {
+			g.updateOptions()
+			g.updateBots()
+		}
+		g.StartIfReady()
+	case engine.Picking:
+		if g.TurnModulus[0] == g.Name {
+			g.updatePicker()
+		}
+	case engine.Playing:
+		if g.MyCards == nil || g.Kingdom == nil {
+			return nil
+		}
+		// H toggles the advisor, except in ranked rooms
+		if inpututil.IsKeyJustPressed(ebiten.KeyH) && !g.Options.Ranked {
+			g.advisor = !g.advisor
+		}
+		// wait until everyone, us included, has seen what we last did
+		if g.Conn.Pending() > 0 {
+			return nil
+		}
+		if g.Kingdom.GameDone() {
+			g.GameDone()
+			return nil
+		}
+		// if there is some special decision we have to make, listen for it
+		if g.Decision != -1 {
+			g.listenForDecision()
+			return nil
+		}
+		// can only interact if it's our turn and we aren't waiting
+		if !g.CanAct() || g.EndPhaseIfDone() || !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+			return nil
+		}
+		// clicks on cards and buttons only do something if they are legal moves
+		if a, ok := g.clickedMove(ebiten.CursorPosition()); ok && g.MakeMove(a) {
+			log.Println(g.Name + " clicked " + a.String())
+		}
+	}
+	return nil
+}
+
+func (g *Game) Draw(screen *ebiten.Image) {
+	switch g.State {
+	case engine.RoomName:
+		g.t.Draw(screen)
+	case engine.Lobby:
+		lobbyMessage := "Room " + g.t.confirmedRoom + "\nHit enter when ready to start\n\n" + g.optionsMessage() + "\nPlayers in this room:\n"
+		// sort player names otherwise it keeps switching them around
+		names := make([]string, len(g.Players))
+
+		i := 0
+		for name := range g.Players {
+			names[i] = name
+			i++
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			lobbyMessage += name + strings.Repeat(" ", engine.MaxNameChars+1-len(name)) + "| "
+			if !g.Players[name].SamePacks(engine.PackChecksums()) {
+				lobbyMessage += "Different card packs!"
+			} else if g.Players[name].Ready {
+				lobbyMessage += "Ready!"
+			} else {
+				lobbyMessage += "Waiting..."
+			}
+			if bs := g.Players[name].Bot; bs != nil {
+				lobbyMessage += " (" + bs.String() + ")"
+				if bots := g.BotsInOrder(); g.PlayerOrder()[0] == g.Name && bots[min(g.botSeat, len(bots)-1)] == name {
+					lobbyMessage += " <"
+				}
+			}
+			lobbyMessage += "\n"
+		}
+		if len(g.BotsInOrder()) > 0 && g.PlayerOrder()[0] == g.Name {
+			lobbyMessage += "\nUp/Down pick a bot, Left/Right change its difficulty, P its personality\n"
+		}
+		ebitenutil.DebugPrint(screen, lobbyMessage)
+	case engine.Picking:
+		g.drawPicker(screen, g.TurnModulus[0] == g.Name)
+	case engine.Playing:
+		currentPlayer := g.TurnModulus[g.Turn%len(g.Players)]
+		promptMsg, decisionSkippable := g.PromptDecision()
+		// draw player's turn message if no prompt
+		if promptMsg == "" {
+			if g.OtherDecisions > 1 {
+				promptMsg = "Waiting for " + strconv.Itoa(g.OtherDecisions) + " players"
+			} else if g.OtherDecisions == 1 {
+				promptMsg = "Waiting for 1 player"
+			} else {
+				promptMsg = currentPlayer + "'s turn: " + g.Phase
+			}
+		}
+		op := &text.DrawOptions{}
+		op.ColorScale.ScaleWithColor(color.White)
+		text.Draw(screen, promptMsg, &text.GoTextFace{
+			Source: MPlusFaceSource,
+			Size:   BigFontSize,
+		}, op)
+		// draw turn stats
+		msg := "Works: " + strconv.Itoa(g.Stats.Works) + " Blessings: " + strconv.Itoa(g.Stats.Blessings) + " Faith: " + strconv.Itoa(g.Stats.Faith)
+		if g.advisor {
+			msg += " (H hides hints)"
+		} else if !g.Options.Ranked {
+			msg += " (H shows hints)"
+		}
+		op = &text.DrawOptions{}
+		op.GeoM.Translate(0, BigFontSize)
+		op.ColorScale.ScaleWithColor(color.White)
+		text.Draw(screen, msg, &text.GoTextFace{
+			Source: MPlusFaceSource,
+			Size:   NormalFontSize,
+		}, op)
+		// draw action log
+		if n := len(g.ActionLog); n > 10 {
+			msg = strings.Join(g.ActionLog[n-10:], "\n")
+		} else {
+			msg = strings.Join(g.ActionLog, "\n")
+		}
+		op = &text.DrawOptions{}
+		op.GeoM.Translate(0, BigFontSize+NormalFontSize)
+		op.ColorScale.ScaleWithColor(color.White)
+		op.LineSpacing = SmallFontSize
+		text.Draw(screen, msg, &text.GoTextFace{
+			Source: MPlusFaceSource,
+			Size:   SmallFontSize,
+		}, op)
+		// draw in play cards (mat, label, cards)
+		vector.DrawFilledRect(screen, 0, InPlayY-10, KingdomMatX, 10+ArtSmallWidth+BigFontSize+10, color.RGBA{245, 133, 63, 255}, true)
+		textOp := &text.DrawOptions{}
+		textOp.GeoM.Translate(0, InPlayY+ArtSmallWidth)
+		textOp.ColorScale.ScaleWithColor(color.White)
+		var inPlayLabel string
+		var inPlayCards []*engine.Card
+		if g.Phase == engine.WorkPhase {
+			inPlayLabel = currentPlayer + "'s Work Cards in Play"
+			inPlayCards = g.InPlayWork
+		} else {
+			inPlayLabel = currentPlayer + "'s Faith Cards in Play"
+			inPlayCards = g.InPlayFaith
+		}
+		text.Draw(screen, inPlayLabel, &text.GoTextFace{
+			Source: MPlusFaceSource,
+			Size:   BigFontSize,
+		}, textOp)
+		for i, c := range inPlayCards {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(float64(i*ArtSmallWidth), InPlayY)
+			screen.DrawImage(smallArt(c), op)
+		}
+		// draw player cards
+		if g.MyCards == nil {
+			return
+		}
+		drawPlayerCards(screen, g.MyCards, g.DecisionRow())
+		// draw kingdom
+		if g.Kingdom == nil {
+			return
+		}
+		drawKingdom(screen, g.Kingdom)
+		// draw which sets the kingdom is from
+		textOp = &text.DrawOptions{}
+		textOp.GeoM.Translate(KingdomMatX, KingdomMatH+10+BigFontSize)
+		textOp.ColorScale.ScaleWithColor(color.White)
+		text.Draw(screen, "Sets: "+g.Options.SetsLabel(), &text.GoTextFace{
+			Source: MPlusFaceSource,
+			Size:   SmallFontSize,
+		}, textOp)
+		g.drawIllegal(screen)
+		g.drawAdvice(screen)
+		// draw buttons
+		if decisionSkippable {
+			// draw the done button where the end phase button goes
+			drawButton(screen, EndPhaseX, EndPhaseY, "Done")
+		} else if g.TurnModulus[g.Turn%len(g.Players)] == g.Name {
+			// draw end phase button if it's our turn
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(EndPhaseX, EndPhaseY)
+			switch g.Phase {
+			case engine.WorkPhase:
+				screen.DrawImage(EndWorkPhase, op)
+			case engine.BlessingPhase:
+				screen.DrawImage(EndBlessingPhase, op)
+				drawButton(screen, PlayAllX, EndPhaseY, "Play All Faith")
+			}
+		}
+		// calculate mouse position to determine hover
+		cursorX, cursorY := ebiten.CursorPosition()
+		var displayX int
+		if cursorX > ScreenWidth/2 {
+			displayX = cursorX - ArtBigWidth
+		} else {
+			displayX = cursorX
+		}
+		if _, c := inHand(g.MyCards, cursorX, cursorY); c != nil {
+			drawCardPreview(screen, c, displayX)
+		} else if _, c := inDecisionRow(g.DecisionRow(), cursorX, cursorY); c != nil {
+			drawCardPreview(screen, c, displayX)
+		} else if p := inKingdom(g.Kingdom, cursorX, cursorY); p != nil {
+			if c := p.Top(); c != nil {
+				drawCardPreview(screen, c, displayX)
+			}
+			drawTextBox(screen, cursorX, cursorY, p.Label())
+		} else if n := inDeck(g.MyCards, cursorX, cursorY); n != -1 {
+			drawTextBox(screen, cursorX, cursorY, strconv.Itoa(n))
+		} else if c, n := inDiscard(g.MyCards, cursorX, cursorY); n != -1 {
+			if c != nil {
+				drawCardPreview(screen, c, displayX)
+			}
+			drawTextBox(screen, cursorX, cursorY, strconv.Itoa(n))
+		}
+	case engine.Ended:
+		msg := "Room " + g.t.confirmedRoom + "\n\n"
+		if g.Result == nil {
+			msg += "Waiting for everyone's final glory..."
+		} else {
+			msg += g.Result.String()
+		}
+		ebitenutil.DebugPrint(screen, msg)
+	}
+}
+
+// returns true if logical screen pixel location x,y is on the end phase button
+func inEndPhaseButton(x, y int) bool {
+	return inButton(x, y, EndPhaseX, EndPhaseY)
+}
+
+// returns true if logical screen pixel location x,y is on the button drawn at buttonX,buttonY
+func inButton(x, y, buttonX, buttonY int) bool {
+	return x > buttonX && x < buttonX+EndPhaseWidth && y > buttonY && y < buttonY+EndPhaseHeight
+}
+
+// draws a button the size of the end phase button with a label
+func drawButton(dst *ebiten.Image, x, y int, label string) {
+	vector.DrawFilledRect(dst, float32(x), float32(y), EndPhaseWidth, EndPhaseHeight, color.RGBA{124, 54, 38, 255}, true)
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(float64(x+EndPhaseWidth/2), float64(y+EndPhaseHeight/2))
+	op.PrimaryAlign = text.AlignCenter
+	op.SecondaryAlign = text.AlignCenter
+	op.ColorScale.ScaleWithColor(color.White)
+	text.Draw(dst, label, &text.GoTextFace{
+		Source: MPlusFaceSource,
+		Size:   NormalFontSize,
+	}, op)
+}
+
+func drawTextBox(dst *ebiten.Image, x, y int, msg string) {
+	width := len(msg) * 10
+	vector.DrawFilledRect(dst, float32(x-width), float32(y-16), float32(width), 16, color.Black, true)
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(float64(x-width), float64(y-16))
+	op.ColorScale.ScaleWithColor(color.White)
+	text.Draw(dst, msg, &text.GoTextFace{
+		Source: MPlusFaceSource,
+		Size:   SmallFontSize,
+	}, op)
+}
+
+// lets the host change the room options with the keyboard, telling everyone about changes
+func (g *Game) updateOptions() {
+	o := g.Options
+	o.Sets = slices.Clone(o.Sets)
+	changed := false
+	// number keys toggle sets
+	for i, set := range engine.CardSets[:min(len(engine.CardSets), 9)] {
+		if inpututil.IsKeyJustPressed(ebiten.KeyDigit1 + ebiten.Key(i)) {
+			if j := slices.Index(o.Sets, set); j != -1 {
+				o.Sets = slices.Delete(o.Sets, j, j+1)
+			} else {
+				o.Sets = append(o.Sets, set)
+			}
+			changed = true
+		}
+	}
+	// M toggles picking at least 3 cards from each set
+	if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+		if o.MinPerSet == 0 {
+			o.MinPerSet = 3
+		} else {
+			o.MinPerSet = 0
+		}
+		changed = true
+	}
+	// C cycles how many different costs are needed
+	if inpututil.IsKeyJustPressed(ebiten.KeyC) {
+		o.Constraints.MinCosts = (o.Constraints.MinCosts + 1) % 5
+		changed = true
+	}
+	// V and D toggle needing a village and draw
+	for i, key := range []ebiten.Key{ebiten.KeyV, ebiten.KeyD} {
+		if inpututil.IsKeyJustPressed(key) {
+			o.Constraints.MinTags = maps.Clone(o.Constraints.MinTags)
+			o.Constraints.MinTags[engine.ConstraintTags[i]] = 1 - o.Constraints.MinTags[engine.ConstraintTags[i]]
+			changed = true
+		}
+	}
+	// R toggles needing a Reaction with Trials
+	if inpututil.IsKeyJustPressed(ebiten.KeyR) {
+		o.Constraints.ReactionWithTrials = !o.Constraints.ReactionWithTrials
+		changed = true
+	}
+	// T cycles the most Trials allowed, from any number down to 0 and back
+	if inpututil.IsKeyJustPressed(ebiten.KeyT) {
+		if o.Constraints.MaxTrials == -1 {
+			o.Constraints.MaxTrials = 3
+		} else {
+			o.Constraints.MaxTrials--
+		}
+		changed = true
+	}
+	// K toggles ranked, which turns off the advisor
+	if inpututil.IsKeyJustPressed(ebiten.KeyK) {
+		o.Ranked = !o.Ranked
+		changed = true
+	}
+	// G cycles the rulesets
+	if inpututil.IsKeyJustPressed(ebiten.KeyG) {
+		i := slices.Index(engine.Rulesets, engine.RulesetNamed(o.Rules))
+		o.Rules = engine.Rulesets[(i+1)%len(engine.Rulesets)].Name
+		changed = true
+	}
+	if changed {
+		g.Conn.Send(append([]string{engine.SetOptions}, o.Encode()...))
+	}
+}
+
+// describes the room options for the lobby
+func (g *Game) optionsMessage() string {
+	msg := "Card sets (host presses 1-9 to toggle):\n"
+	for i, set := range engine.CardSets[:min(len(engine.CardSets), 9)] {
+		if slices.Contains(g.Options.Sets, set) {
+			msg += "[x] "
+		} else {
+			msg += "[ ] "
+		}
+		msg += strconv.Itoa(i+1) + " " + set + "\n"
+	}
+	if g.Options.MinPerSet > 0 {
+		msg += "At least " + strconv.Itoa(g.Options.MinPerSet) + " from each set (M to toggle)\n"
+	} else {
+		msg += "Any number from each set (M to toggle)\n"
+	}
+	msg += "Random kingdoms need: " + g.Options.Constraints.String() + "\n(C costs, V village, D draw, R Reaction, T Trials)\n"
+	rules := engine.RulesetNamed(g.Options.Rules)
+	msg += "Rules: " + rules.Name + " (G to change)\n  " + rules.String() + "\n"
+	if g.Options.Ranked {
+		msg += "Ranked, no hints (K to toggle)\n"
+	} else {
+		msg += "Unranked, H shows hints in game (K to toggle)\n"
+	}
+	return msg
+}
+
+func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	return ScreenWidth, ScreenHeight
+}
+
+// runs the game, or the sim, tournament or env command named by the first argument
+func Main() {
+	// kingdom-of-heaven sim plays bot games without a window
+	if len(os.Args) > 1 && os.Args[1] == "sim" {
+		engine.RunSim(os.Args[2:])
+		return
+	}
+	// and kingdom-of-heaven tournament plays strategies against each other and rates them
+	if len(os.Args) > 1 && os.Args[1] == "tournament" {
+		engine.RunTournament(os.Args[2:])
+		return
+	}
+	// and kingdom-of-heaven env lets a trainer play over stdin and stdout
+	if len(os.Args) > 1 && os.Args[1] == "env" {
+		engine.RunEnv(os.Args[2:], os.Stdin, os.Stdout)
+		return
+	}
+	packDir := flag.String("packs", "packs", "directory of card packs to load")
+	resultsPath := flag.String("results", "results.jsonl", "file to add game results to, or empty to not record them")
+	seed := flag.Int64("seed", 0, "seed for the game if we host, to play a recorded game again, or 0 for a new one")
+	flag.Parse()
+	engine.LoadPacks(*packDir)
+
+	g := &Game{Game: engine.NewGame()}
+	g.ResultsPath = *resultsPath
+	g.Seed = *seed
+	if g.Seed == 0 {
+		g.Seed = time.Now().UnixNano()
+	}
+	g.RNG = rand.New(rand.NewSource(g.Seed))
+
+	err := ebiten.RunGame(g)
+	if err != nil {
+		panic(err)
+	}
+	if g.Conn == nil {
+		return
+	}
+	g.Conn.Send([]string{engine.LeftLobby, g.Name})
+	// spin until game disposes everything
+	for g.State != engine.Closed {
+	}
+}
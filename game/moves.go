@@ -0,0 +1,98 @@
+// turns clicks into moves, and shows which moves are legal
+package game
+
+import (
+	"image/color"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/zehongharryqu/kingdom-of-heaven/engine"
+)
+
+// how cards that can't be clicked right now are greyed out
+var IllegalColor = color.RGBA{0, 0, 0, 160}
+
+// the move a click at logical screen pixel location x,y asks for, whether or not it is legal
+func (g *Game) clickedMove(x, y int) (engine.Action, bool) {
+	if g.Decision != -1 {
+		if inEndPhaseButton(x, y) {
+			return engine.Action{Kind: engine.ActionSkip}, true
+		}
+		var c *engine.Card
+		switch g.DecisionSource() {
+		case engine.FromHand:
+			_, c = inHand(g.MyCards, x, y)
+		case engine.FromRevealed, engine.FromDiscard:
+			_, c = inDecisionRow(g.DecisionRow(), x, y)
+		case engine.FromKingdom:
+			if p := inKingdom(g.Kingdom, x, y); p != nil {
+				c = p.Top()
+			}
+		}
+		return engine.Action{Kind: engine.ActionChoose, Card: c}, c != nil
+	}
+	if inEndPhaseButton(x, y) {
+		return engine.Action{Kind: engine.ActionEndPhase}, true
+	}
+	if g.Phase == engine.BlessingPhase && inButton(x, y, PlayAllX, EndPhaseY) {
+		return engine.Action{Kind: engine.ActionPlayFaith}, true
+	}
+	if _, c := inHand(g.MyCards, x, y); c != nil {
+		if g.Phase == engine.WorkPhase {
+			return engine.Action{Kind: engine.ActionPlayWork, Card: c}, true
+		}
+		return engine.Action{Kind: engine.ActionPlayFaith, Card: c}, true
+	}
+	if p := inKingdom(g.Kingdom, x, y); p != nil && p.Top() != nil {
+		return engine.Action{Kind: engine.ActionBuy, Card: p.Top()}, true
+	}
+	return engine.Action{}, false
+}
+
+// greys out the cards in hand, revealed and in the kingdom that can't be clicked right now, while we
+// have something to do
+func (g *Game) drawIllegal(screen *ebiten.Image) {
+	moves := g.LegalMoves()
+	if len(moves) == 0 {
+		return
+	}
+	legal := func(c *engine.Card, kinds ...int) bool {
+		return slices.ContainsFunc(moves, func(a engine.Action) bool { return a.Card == c && slices.Contains(kinds, a.Kind) })
+	}
+	grey := func(x, y int) {
+		vector.DrawFilledRect(screen, float32(x), float32(y), ArtSmallWidth, ArtSmallWidth, IllegalColor, true)
+	}
+	from := -1
+	if g.Decision != -1 {
+		from = g.DecisionSource()
+	}
+	for i, c := range g.MyCards.Hand {
+		if !legal(c, engine.ActionPlayWork, engine.ActionPlayFaith) && !(from == engine.FromHand && legal(c, engine.ActionChoose)) {
+			grey((ScreenWidth-ArtSmallWidth*len(g.MyCards.Hand))/2+i*ArtSmallWidth, ScreenHeight-ArtSmallWidth)
+		}
+	}
+	row := g.DecisionRow()
+	for i, c := range row {
+		if !((from == engine.FromRevealed || from == engine.FromDiscard) && legal(c, engine.ActionChoose)) {
+			grey((ScreenWidth-ArtSmallWidth*len(row))/2+i*ArtSmallWidth, DecisionY)
+		}
+	}
+	for _, p := range g.Kingdom.Piles {
+		if c := p.Top(); c != nil && !legal(c, engine.ActionBuy) && !(from == engine.FromKingdom && legal(c, engine.ActionChoose)) {
+			grey(slotPosition(p.Slot))
+		}
+	}
+}
+
+// react to local decisions made
+func (g *Game) listenForDecision() {
+	if !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return
+	}
+	// done skips the decision if it can be skipped, and clicking a choice picks it
+	if a, ok := g.clickedMove(ebiten.CursorPosition()); ok {
+		g.MakeMove(a)
+	}
+}
@@ -0,0 +1,143 @@
+// draws the picker and lets the host pick with the mouse
+package game
+
+import (
+	"image/color"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/zehongharryqu/kingdom-of-heaven/engine"
+)
+
+// picker layout
+const (
+	PickerX       = 20
+	PickerY       = 60
+	PickerPerRow  = 12
+	PickerRowGap  = 10
+	RandomizeX    = 20
+	PresetX       = 245
+	StartX        = 470
+	PickerButtonY = 370
+)
+
+// given logical screen pixel location x,y returns the card on the picker there
+func (g *Game) inPicker(x, y int) *engine.Card {
+	cards := g.PickerCards()
+	for i, c := range cards {
+		cardX, cardY := PickerX+(i%PickerPerRow)*ArtSmallWidth, PickerY+(i/PickerPerRow)*(ArtSmallWidth+PickerRowGap)
+		if x > cardX && x < cardX+ArtSmallWidth && y > cardY && y < cardY+ArtSmallWidth {
+			return c
+		}
+	}
+	return nil
+}
+
+// lets the host pick the kingdom with the mouse
+func (g *Game) updatePicker() {
+	left, right := inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft), inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight)
+	if !left && !right {
+		return
+	}
+	cursorX, cursorY := ebiten.CursorPosition()
+	kp := engine.KingdomPicks{Picked: slices.Clone(g.Picks.Picked), Banned: slices.Clone(g.Picks.Banned)}
+	if c := g.inPicker(cursorX, cursorY); c != nil {
+		if left {
+			// pick or unpick, unbanning it if needed
+			if i := slices.Index(kp.Picked, c); i != -1 {
+				kp.Picked = slices.Delete(kp.Picked, i, i+1)
+			} else if len(kp.Picked) < engine.KingdomSize {
+				kp.Picked = append(kp.Picked, c)
+				kp.Banned = slices.DeleteFunc(kp.Banned, func(b *engine.Card) bool { return b == c })
+			}
+		} else {
+			// ban or unban, unpicking it if needed
+			if i := slices.Index(kp.Banned, c); i != -1 {
+				kp.Banned = slices.Delete(kp.Banned, i, i+1)
+			} else {
+				kp.Banned = append(kp.Banned, c)
+				kp.Picked = slices.DeleteFunc(kp.Picked, func(p *engine.Card) bool { return p == c })
+			}
+		}
+		g.SendPicks(kp)
+		return
+	}
+	if !left {
+		return
+	}
+	switch {
+	case inButton(cursorX, cursorY, RandomizeX, PickerButtonY):
+		kp.Picked = engine.PickKingdom(g.Options, kp.Banned, g.RNG)
+		g.SendPicks(kp)
+	case inButton(cursorX, cursorY, PresetX, PickerButtonY):
+		// load the preset after the one last loaded
+		i := slices.IndexFunc(engine.KingdomPresets, func(p engine.KingdomPreset) bool { return p.Name == g.Picks.Preset })
+		preset := engine.KingdomPresets[(i+1)%len(engine.KingdomPresets)]
+		kp.Picked = slices.Clone(preset.Kingdom)
+		kp.Banned = slices.DeleteFunc(kp.Banned, func(b *engine.Card) bool { return slices.Contains(kp.Picked, b) })
+		kp.Preset = preset.Name
+		g.SendPicks(kp)
+	case inButton(cursorX, cursorY, StartX, PickerButtonY):
+		if len(kp.Picked) == engine.KingdomSize {
+			// the game starts from our seed, which everyone records
+			g.Conn.Send([]string{engine.SetSeed, strconv.FormatInt(g.Seed, 10)})
+			g.Conn.Send(append([]string{engine.SetKingdom}, engine.CardNames(kp.Picked)...))
+		}
+	}
+}
+
+// draws the picker, with the picked cards outlined and the banned ones crossed out
+func (g *Game) drawPicker(screen *ebiten.Image, host bool) {
+	var msg string
+	if host {
+		msg = "Click to pick a card, right click to ban it"
+	} else {
+		msg = "Waiting for the host to pick the kingdom"
+	}
+	msg += "\nPicked " + strconv.Itoa(len(g.Picks.Picked)) + "/" + strconv.Itoa(engine.KingdomSize)
+	if g.Picks.Preset != "" {
+		msg += " from " + g.Picks.Preset
+	}
+	if unmet := g.Options.Constraints.Unmet(g.Picks.Picked); len(unmet) > 0 {
+		msg += "; " + strings.Join(unmet, ", ")
+	}
+	op := &text.DrawOptions{}
+	op.ColorScale.ScaleWithColor(color.White)
+	op.LineSpacing = BigFontSize
+	text.Draw(screen, msg, &text.GoTextFace{
+		Source: MPlusFaceSource,
+		Size:   NormalFontSize,
+	}, op)
+	for i, c := range g.PickerCards() {
+		x, y := float32(PickerX+(i%PickerPerRow)*ArtSmallWidth), float32(PickerY+(i/PickerPerRow)*(ArtSmallWidth+PickerRowGap))
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(x), float64(y))
+		screen.DrawImage(smallArt(c), op)
+		if slices.Contains(g.Picks.Picked, c) {
+			vector.StrokeRect(screen, x, y, ArtSmallWidth, ArtSmallWidth, 3, color.RGBA{0, 192, 96, 255}, true)
+		} else if slices.Contains(g.Picks.Banned, c) {
+			vector.DrawFilledRect(screen, x, y, ArtSmallWidth, ArtSmallWidth, color.RGBA{96, 0, 0, 160}, true)
+		}
+	}
+	if host {
+		drawButton(screen, RandomizeX, PickerButtonY, "Randomize")
+		drawButton(screen, PresetX, PickerButtonY, "Next Preset")
+		if len(g.Picks.Picked) == engine.KingdomSize {
+			drawButton(screen, StartX, PickerButtonY, "Start")
+		}
+	}
+	// show the hovered card
+	cursorX, cursorY := ebiten.CursorPosition()
+	if c := g.inPicker(cursorX, cursorY); c != nil {
+		displayX := cursorX
+		if cursorX > ScreenWidth/2 {
+			displayX = cursorX - ArtBigWidth
+		}
+		drawCardPreview(screen, c, displayX)
+	}
+}
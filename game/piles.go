@@ -0,0 +1,142 @@
+// draws the cards on the table and finds the ones under the mouse
+package game
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/zehongharryqu/kingdom-of-heaven/engine"
+)
+
+// draws the cards, with row as the cards for the current decision
+func drawPlayerCards(screen *ebiten.Image, pc *engine.PlayerCards, row []*engine.Card) {
+	// decision
+	offset := (ScreenWidth - ArtSmallWidth*len(row)) / 2
+	for i, c := range row {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(offset+i*ArtSmallWidth), DecisionY)
+		screen.DrawImage(smallArt(c), op)
+	}
+	// deck
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(DeckPileX, DiscardDeckPileY)
+	screen.DrawImage(DeckSmall, op)
+	// discard
+	op = &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(DiscardPileX, DiscardDeckPileY)
+	if len(pc.Discard) > 0 {
+		screen.DrawImage(smallArt(pc.Discard[len(pc.Discard)-1]), op)
+	} else {
+		screen.DrawImage(DiscardSmall, op)
+	}
+	// hand
+	offset = (ScreenWidth - ArtSmallWidth*len(pc.Hand)) / 2
+	for i, c := range pc.Hand {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(offset+i*ArtSmallWidth), ScreenHeight-ArtSmallWidth)
+		screen.DrawImage(smallArt(c), op)
+	}
+}
+
+// given logical screen pixel location x,y returns the card there in the decision row
+func inDecisionRow(row []*engine.Card, x, y int) (int, *engine.Card) {
+	localX := x - ((ScreenWidth - ArtSmallWidth*len(row)) / 2)
+	localY := y - DecisionY
+	if localX > 0 && localX < ArtSmallWidth*len(row) && localY > 0 && localY < ArtSmallWidth {
+		return localX / ArtSmallWidth, row[localX/ArtSmallWidth]
+	}
+	return -1, nil
+}
+
+// given logical screen pixel location x,y returns the card in hand there
+func inHand(pc *engine.PlayerCards, x, y int) (int, *engine.Card) {
+	localX := x - ((ScreenWidth - ArtSmallWidth*len(pc.Hand)) / 2)
+	localY := y - (ScreenHeight - ArtSmallWidth)
+	if localX > 0 && localX < ArtSmallWidth*len(pc.Hand) && localY > 0 && localY < ArtSmallWidth {
+		return localX / ArtSmallWidth, pc.Hand[localX/ArtSmallWidth]
+	}
+	return -1, nil
+}
+
+// given logical screen pixel location x,y returns the number of cards in deck if hovered
+func inDeck(pc *engine.PlayerCards, x, y int) int {
+	if x > DeckPileX && x < DeckPileX+ArtSmallWidth && y > DiscardDeckPileY && y < DiscardDeckPileY+ArtSmallWidth {
+		return len(pc.Deck)
+	}
+	return -1
+}
+
+// given logical screen pixel location x,y returns the top card and the number of cards in discard if hovered
+func inDiscard(pc *engine.PlayerCards, x, y int) (*engine.Card, int) {
+	if x > DiscardPileX && x < DiscardPileX+ArtSmallWidth && y > DiscardDeckPileY && y < DiscardDeckPileY+ArtSmallWidth {
+		if n := len(pc.Discard); n > 0 {
+			return pc.Discard[n-1], n
+		} else {
+			return nil, 0
+		}
+	}
+	return nil, -1
+}
+
+const (
+	// the kingdom piles are laid out in a grid on the mat
+	PileGridX   = 340
+	PileGridY   = 10
+	PileGridGap = 60
+
+	DiscardPileX     = 520
+	DeckPileX        = 580
+	DiscardDeckPileY = 370
+	KingdomMatX      = 330
+	KingdomMatW      = 310
+	KingdomMatH      = 240
+)
+
+// the logical screen pixel location of a slot in the grid on the mat
+func slotPosition(slot int) (int, int) {
+	return PileGridX + slot%engine.PileGridCols*PileGridGap, PileGridY + slot/engine.PileGridCols*PileGridGap
+}
+
+// draws the kingdom piles and released pile
+func drawKingdom(screen *ebiten.Image, k *engine.Kingdom) {
+	// draw mat
+	vector.DrawFilledRect(screen, KingdomMatX, 0, KingdomMatW, KingdomMatH+10+BigFontSize, color.RGBA{124, 54, 38, 255}, true)
+	// draw mat label
+	textOp := &text.DrawOptions{}
+	textOp.GeoM.Translate(KingdomMatX, KingdomMatH)
+	textOp.ColorScale.ScaleWithColor(color.White)
+	text.Draw(screen, "Verse Piles", &text.GoTextFace{
+		Source: MPlusFaceSource,
+		Size:   BigFontSize,
+	}, textOp)
+	// draw kingdom piles
+	for _, p := range k.Piles {
+		if c := p.Top(); c != nil {
+			op := &ebiten.DrawImageOptions{}
+			x, y := slotPosition(p.Slot)
+			op.GeoM.Translate(float64(x), float64(y))
+			screen.DrawImage(smallArt(c), op)
+		}
+	}
+	// draw released pile
+	op := &ebiten.DrawImageOptions{}
+	x, y := slotPosition(k.ReleasedSlot)
+	op.GeoM.Translate(float64(x), float64(y))
+	if n := len(k.Released); n > 0 {
+		screen.DrawImage(smallArt(k.Released[n-1]), op)
+	} else {
+		screen.DrawImage(ReleaseSmall, op)
+	}
+}
+
+// given logical screen pixel location x,y returns the pile there
+func inKingdom(k *engine.Kingdom, x, y int) *engine.Pile {
+	for _, p := range k.Piles {
+		if pileX, pileY := slotPosition(p.Slot); x > pileX && x < pileX+ArtSmallWidth && y > pileY && y < pileY+ArtSmallWidth {
+			return p
+		}
+	}
+	return nil
+}
@@ -1,30 +1,15 @@
 // sets up the pulsar client for the player
-package main
+package game
 
 import (
 	"context"
 	"fmt"
-	"math/rand"
-	"strconv"
-	"strings"
-
 	"log"
+	"strings"
+	"sync/atomic"
 
 	"github.com/apache/pulsar-client-go/pulsar"
-)
-
-// message types
-const (
-	JoinedLobby  = "J"
-	LeftLobby    = "L"
-	ToggledReady = "TR"
-	SetKingdom   = "SK"
-	EndPhase     = "E"
-	Played       = "P"
-	Bought       = "B"
-	Gained       = "G"
-	Glory        = "Gl"
-	CardSpecific = "C"
+	"github.com/zehongharryqu/kingdom-of-heaven/engine"
 )
 
 type PulsarClient struct {
@@ -32,6 +17,8 @@ type PulsarClient struct {
 	client               pulsar.Client
 	producer             pulsar.Producer
 	consumer             pulsar.Consumer
+	// how many messages we have sent, and how many of those we have received back
+	sent, received atomic.Int64
 	// tableView            pulsar.TableView
 	// consumeCh            chan pulsar.ConsumerMessage
 	// // exclude type
@@ -42,6 +29,23 @@ type PulsarClient struct {
 	// closeCh chan struct{}
 }
 
+func (c *PulsarClient) Send(message []string) {
+	c.sent.Add(1)
+	producerSend(c.producer, message)
+}
+
+func (c *PulsarClient) Receive() []string {
+	message, producerName := consumerReceive(c.consumer)
+	if producerName == c.producer.Name() {
+		c.received.Add(1)
+	}
+	return message
+}
+
+func (c *PulsarClient) Pending() int {
+	return int(c.sent.Load() - c.received.Load())
+}
+
 func (c *PulsarClient) Close() {
 	if err := c.consumer.Unsubscribe(); err != nil {
 		log.Fatal(err)
@@ -55,7 +59,7 @@ func (c *PulsarClient) Close() {
 	// close(c.consumeCh)
 }
 
-func newPulsarClient(roomName, playerName string) *PulsarClient {
+func newPulsarClient(roomName, playerName string, pid int) *PulsarClient {
 	oauth := pulsar.NewAuthenticationOAuth2(map[string]string{
 		"type":       "client_credentials",
 		"issuerUrl":  "https://auth.streamnative.cloud/",
@@ -80,7 +84,7 @@ func newPulsarClient(roomName, playerName string) *PulsarClient {
 		log.Fatal(err)
 	}
 
-	producerSend(producer, []string{JoinedLobby, playerName, strconv.Itoa(rand.Intn(10))})
+	producerSend(producer, engine.JoinMessage(playerName, pid))
 
 	consumer, err := client.Subscribe(pulsar.ConsumerOptions{
 		Topic:                       "persistent://public/default/" + roomName,
@@ -92,7 +96,10 @@ func newPulsarClient(roomName, playerName string) *PulsarClient {
 		log.Fatal(err)
 	}
 
-	return &PulsarClient{roomName, playerName, client, producer, consumer}
+	c := &PulsarClient{roomName: roomName, playerName: playerName, client: client, producer: producer, consumer: consumer}
+	// the join message comes back to us too
+	c.sent.Add(1)
+	return c
 }
 
 func producerSend(producer pulsar.Producer, message []string) {
@@ -106,7 +113,8 @@ func producerSend(producer pulsar.Producer, message []string) {
 	}
 }
 
-func consumerReceive(consumer pulsar.Consumer) []string {
+// returns the next message and the name of the producer that sent it
+func consumerReceive(consumer pulsar.Consumer) ([]string, string) {
 	msg, err := consumer.Receive(context.Background())
 	if err != nil {
 		log.Fatal(err)
@@ -116,5 +124,5 @@ func consumerReceive(consumer pulsar.Consumer) []string {
 		msg.ID(), string(msg.Payload()))
 	consumer.Ack(msg)
 	message := strings.Split(string(msg.Payload()), "\\")
-	return message
+	return message, msg.ProducerName()
 }
@@ -1,12 +1,14 @@
 // the screen where the user picks their room and name
-package main
+package game
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/zehongharryqu/kingdom-of-heaven/engine"
 )
 
 // repeatingKeyPressed return true when key is pressed considering the repeat state.
@@ -31,6 +33,9 @@ type Typewriter struct {
 	counter       int    // frame counter for blink
 	confirmedRoom string
 	confirmedName string
+	// whether we are asking how many bots to play against instead of the room, and how many
+	vsBots bool
+	bots   int
 }
 
 func (t *Typewriter) Update() error {
@@ -41,8 +46,8 @@ func (t *Typewriter) Update() error {
 	t.currentText += string(t.runes)
 
 	// Adjust the string to be at most MaxNameChars characters
-	if len(t.currentText) > MaxNameChars {
-		t.currentText = t.currentText[:MaxNameChars]
+	if len(t.currentText) > engine.MaxNameChars {
+		t.currentText = t.currentText[:engine.MaxNameChars]
 	}
 
 	// Take out backslashes
@@ -50,10 +55,23 @@ func (t *Typewriter) Update() error {
 		t.currentText = strings.ReplaceAll(t.currentText, "\\", "")
 	}
 
+	// Tab switches between joining a room and playing vs bots
+	if t.confirmedRoom == "" && inpututil.IsKeyJustPressed(ebiten.KeyTab) {
+		t.vsBots = !t.vsBots
+		t.currentText = ""
+	}
+
 	// If the enter key is pressed, confirm the current text
 	if repeatingKeyPressed(ebiten.KeyEnter) || repeatingKeyPressed(ebiten.KeyNumpadEnter) {
 		if len(t.currentText) > 0 {
-			if t.confirmedRoom == "" {
+			if t.confirmedRoom == "" && t.vsBots {
+				// play in a room of our own
+				if n, err := strconv.Atoi(t.currentText); err == nil && n >= 1 && n <= engine.MaxBots {
+					t.bots = n
+					t.confirmedRoom = "vs " + t.currentText + " bots"
+				}
+				t.currentText = ""
+			} else if t.confirmedRoom == "" {
 				t.confirmedRoom = t.currentText
 				t.currentText = ""
 			} else if t.confirmedName == "" {
@@ -80,7 +98,10 @@ func (t *Typewriter) Draw(screen *ebiten.Image) {
 	if t.counter%60 < 30 {
 		currentTextDisplay += "_"
 	}
-	message := "Please enter the name of the room you want to join:\n"
+	message := "Please enter the name of the room you want to join (or press Tab to Play vs Bots):\n"
+	if t.vsBots {
+		message = "How many bots do you want to play against? (1-" + strconv.Itoa(engine.MaxBots) + ", or press Tab to join a room)\n"
+	}
 	if t.confirmedRoom != "" {
 		message += t.confirmedRoom + "\nPlease enter your name:\n"
 	}